@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IDs of the built-in checks
+const (
+	CheckTitle       = "title"
+	CheckH1          = "h1"
+	CheckLinkWarning = "link-warning"
+	CheckNoindex     = "noindex"
+)
+
+// titleCheck reports the pages without a title
+type titleCheck struct{}
+
+func (titleCheck) ID() string {
+	return CheckTitle
+}
+
+func (titleCheck) Description() string {
+	return "The page has a title."
+}
+
+func (titleCheck) Severity() string {
+	return SeverityWarning
+}
+
+func (titleCheck) Run(doc *Document) []Finding {
+	if strings.TrimSpace(doc.Title) == "" {
+		return []Finding{{Message: "The page has no title."}}
+	}
+	return nil
+}
+
+// h1Check reports the pages without an h1 heading or with several
+type h1Check struct{}
+
+func (h1Check) ID() string {
+	return CheckH1
+}
+
+func (h1Check) Description() string {
+	return "The page has a single h1 heading."
+}
+
+func (h1Check) Severity() string {
+	return SeverityWarning
+}
+
+func (h1Check) Run(doc *Document) []Finding {
+	switch h1 := doc.HeadingsCount["h1"]; {
+	case h1 == 0:
+		return []Finding{{Message: "The page has no h1 heading."}}
+	case h1 > 1:
+		return []Finding{{Message: fmt.Sprintf("The page has %d h1 headings.", h1)}}
+	}
+	return nil
+}
+
+// linkWarningCheck reports the link warnings of the document, see linkWarning
+type linkWarningCheck struct{}
+
+func (linkWarningCheck) ID() string {
+	return CheckLinkWarning
+}
+
+func (linkWarningCheck) Description() string {
+	return "The links of the page lead somewhere."
+}
+
+func (linkWarningCheck) Severity() string {
+	return SeverityNote
+}
+
+func (linkWarningCheck) Run(doc *Document) []Finding {
+	var findings []Finding
+	for _, w := range doc.LinkWarnings {
+		findings = append(findings, Finding{Message: w.Message, Target: w.Href})
+	}
+	return findings
+}
+
+// noindexCheck reports the pages asking search engines not to index them
+type noindexCheck struct{}
+
+func (noindexCheck) ID() string {
+	return CheckNoindex
+}
+
+func (noindexCheck) Description() string {
+	return "The page can be indexed by search engines."
+}
+
+func (noindexCheck) Severity() string {
+	return SeverityNote
+}
+
+func (noindexCheck) Run(doc *Document) []Finding {
+	if doc.Noindex {
+		return []Finding{{Message: "The page asks search engines not to index it."}}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    Check
+		doc      Document
+		expected []Finding
+	}{
+		{
+			name:  "Title",
+			check: titleCheck{},
+			doc:   Document{Title: "Home"},
+		},
+		{
+			name:     "Missing title",
+			check:    titleCheck{},
+			expected: []Finding{{Message: "The page has no title."}},
+		},
+		{
+			name:     "Blank title",
+			check:    titleCheck{},
+			doc:      Document{Title: " "},
+			expected: []Finding{{Message: "The page has no title."}},
+		},
+		{
+			name:  "Single h1",
+			check: h1Check{},
+			doc:   Document{HeadingsCount: map[string]int{"h1": 1, "h2": 1}},
+		},
+		{
+			name:     "No h1",
+			check:    h1Check{},
+			doc:      Document{HeadingsCount: map[string]int{"h2": 1}},
+			expected: []Finding{{Message: "The page has no h1 heading."}},
+		},
+		{
+			name:     "Several h1",
+			check:    h1Check{},
+			doc:      Document{HeadingsCount: map[string]int{"h1": 2}},
+			expected: []Finding{{Message: "The page has 2 h1 headings."}},
+		},
+		{
+			name:  "No link warnings",
+			check: linkWarningCheck{},
+		},
+		{
+			name:  "Link warnings",
+			check: linkWarningCheck{},
+			doc: Document{LinkWarnings: []LinkWarning{
+				{Href: "#", Category: LinkCategoryFragment, Message: "The link only points to '#' and leads nowhere."},
+				{Category: LinkCategoryEmpty, Message: "The link has an empty href and leads nowhere."},
+			}},
+			expected: []Finding{
+				{Message: "The link only points to '#' and leads nowhere.", Target: "#"},
+				{Message: "The link has an empty href and leads nowhere."},
+			},
+		},
+		{
+			name:  "Indexed page",
+			check: noindexCheck{},
+		},
+		{
+			name:     "Noindex page",
+			check:    noindexCheck{},
+			doc:      Document{Noindex: true},
+			expected: []Finding{{Message: "The page asks search engines not to index it."}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings := test.check.Run(&test.doc)
+			if !reflect.DeepEqual(findings, test.expected) {
+				t.Errorf("Expected findings %+v, got %+v", test.expected, findings)
+			}
+		})
+	}
+}
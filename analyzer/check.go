@@ -0,0 +1,195 @@
+package analyzer
+
+import (
+	"slices"
+
+	"github.com/isurukdniss/webpage-analyzer/utils"
+
+	"golang.org/x/net/html"
+)
+
+// Document is the parsed page the checks are run on, with the metadata of its fetch
+type Document struct {
+	Root *html.Node
+	// URL is the final URL of the page, after the redirects
+	URL string
+	// BaseURL is the URL the relative links of the document are resolved against
+	BaseURL    string
+	StatusCode int
+	Redirects  []utils.Redirect
+	Charset    utils.Charset
+	// UserAgent is the User-Agent the page was requested with
+	UserAgent string
+	// Title, HeadingsCount, Noindex and LinkWarnings are collected by the walk of the document,
+	// they are not set yet when the element checks visit the elements. Noindex reports that the
+	// X-Robots-Tag header or a robots meta tag asks not to index the page.
+	Title         string
+	HeadingsCount map[string]int
+	Noindex       bool
+	LinkWarnings  []LinkWarning
+}
+
+// Check is a rule the parsed pages are checked against. The findings of the enabled checks are
+// added to the result of the analysis. Checks are run by concurrent analyses and must not keep
+// state between two runs.
+type Check interface {
+	// ID identifies the check in the findings and in the configuration, eg. "title"
+	ID() string
+	// Description tells what the check expects from a page
+	Description() string
+	// Severity is the highest severity of the findings of the check
+	Severity() string
+	// Run returns the issues found in the document. The check and the severity of the
+	// findings default to the ID and the severity of the check.
+	Run(doc *Document) []Finding
+}
+
+// checks are the registered checks in the order they are run
+var checks = []Check{titleCheck{}, h1Check{}, linkWarningCheck{}, noindexCheck{}}
+
+// RegisterCheck adds a check run by the analyses, or replaces the registered check with the
+// same ID. It is not safe to call concurrently with the analyses, so checks are registered at
+// start up.
+func RegisterCheck(c Check) {
+	for i, registered := range checks {
+		if registered.ID() == c.ID() {
+			checks[i] = c
+			return
+		}
+	}
+	checks = append(checks, c)
+}
+
+// LookupCheck returns the registered check with the ID
+func LookupCheck(id string) (Check, bool) {
+	for _, c := range checks {
+		if c.ID() == id {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// CheckIDs returns the IDs of the registered checks in the order they are run
+func CheckIDs() []string {
+	ids := make([]string, len(checks))
+	for i, c := range checks {
+		ids[i] = c.ID()
+	}
+	return ids
+}
+
+// enabledChecks returns the registered checks that are not disabled
+func (a *Analyzer) enabledChecks() []Check {
+	enabled := make([]Check, 0, len(checks))
+	for _, c := range checks {
+		if !slices.Contains(a.DisabledChecks, c.ID()) {
+			enabled = append(enabled, c)
+		}
+	}
+	return enabled
+}
+
+// checkRun runs the enabled checks on a document. The element checks visit the elements during
+// the walk of analyzeDoc, so that the document is walked once, and the other checks are run on
+// the whole document afterwards.
+type checkRun struct {
+	enabled []Check
+	doc     *Document
+	// elementFindings holds the findings of the element checks, by index in enabled
+	elementFindings [][]Finding
+}
+
+func newCheckRun(enabled []Check, doc *Document) *checkRun {
+	return &checkRun{enabled: enabled, doc: doc, elementFindings: make([][]Finding, len(enabled))}
+}
+
+// visit runs the element checks on an element of the document
+func (r *checkRun) visit(n *html.Node) {
+	for i, c := range r.enabled {
+		if ec, ok := c.(elementCheck); ok && ec.matches(n) {
+			r.elementFindings[i] = append(r.elementFindings[i], ec.visit(r.doc, n)...)
+		}
+	}
+}
+
+// findings runs the other checks and returns the findings in the order of the checks
+func (r *checkRun) findings() []Finding {
+	var findings []Finding
+	for i, c := range r.enabled {
+		found := r.elementFindings[i]
+		if _, ok := c.(elementCheck); !ok {
+			found = c.Run(r.doc)
+		}
+		for _, f := range found {
+			if f.Check == "" {
+				f.Check = c.ID()
+			}
+			if f.Severity == "" {
+				f.Severity = c.Severity()
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// elementCheck is a check that visits the elements of the document with the given tag names
+type elementCheck struct {
+	id          string
+	description string
+	severity    string
+	tags        []string
+	visit       func(doc *Document, n *html.Node) []Finding
+}
+
+// NewElementCheck returns a check that calls visit for every element of the document with one
+// of the tag names, in document order, or for every element when no tag name is given. It
+// suits the checks of single elements, eg. the images without an alt attribute.
+func NewElementCheck(id, description, severity string, tags []string, visit func(doc *Document, n *html.Node) []Finding) Check {
+	return elementCheck{id: id, description: description, severity: severity, tags: tags, visit: visit}
+}
+
+func (c elementCheck) ID() string {
+	return c.id
+}
+
+func (c elementCheck) Description() string {
+	return c.description
+}
+
+func (c elementCheck) Severity() string {
+	return c.severity
+}
+
+func (c elementCheck) Run(doc *Document) []Finding {
+	var findings []Finding
+	for _, n := range elements(doc.Root, c.tags...) {
+		findings = append(findings, c.visit(doc, n)...)
+	}
+	return findings
+}
+
+// matches reports whether the check visits the element
+func (c elementCheck) matches(n *html.Node) bool {
+	return len(c.tags) == 0 || slices.Contains(c.tags, n.Data)
+}
+
+// elements returns the elements of the tree with one of the tag names in document order, or
+// all the elements when no tag name is given
+func elements(n *html.Node, tags ...string) []*html.Node {
+	var found []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (len(tags) == 0 || slices.Contains(tags, n.Data)) {
+			found = append(found, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	if n != nil {
+		walk(n)
+	}
+	return found
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+	"golang.org/x/net/html"
+
+	"github.com/isurukdniss/webpage-analyzer/utils/mocks"
+)
+
+// imgAltCheck reports the images without an alt attribute
+var imgAltCheck = NewElementCheck("img-alt", "The images have a text alternative.", SeverityWarning, []string{"img"},
+	func(doc *Document, n *html.Node) []Finding {
+		if hasAttribute(n, "alt") {
+			return nil
+		}
+		return []Finding{{Message: "The image has no alt attribute.", Target: utilsInstance.ExtractAttribute(n, "src")}}
+	})
+
+// statusCheck reports the pages of the documents returned with another status than 200
+type statusCheck struct{}
+
+func (statusCheck) ID() string {
+	return "status"
+}
+
+func (statusCheck) Description() string {
+	return "The page is returned with the status code 200."
+}
+
+func (statusCheck) Severity() string {
+	return SeverityError
+}
+
+func (statusCheck) Run(doc *Document) []Finding {
+	if doc.StatusCode != http.StatusOK {
+		return []Finding{{Message: http.StatusText(doc.StatusCode), Severity: SeverityWarning}}
+	}
+	return nil
+}
+
+// registerChecks registers the checks for the duration of the test
+func registerChecks(t *testing.T, registered ...Check) {
+	saved := checks
+	checks = append([]Check(nil), checks...)
+	for _, c := range registered {
+		RegisterCheck(c)
+	}
+	t.Cleanup(func() { checks = saved })
+}
+
+func TestRegisterCheck(t *testing.T) {
+	registerChecks(t, imgAltCheck)
+
+	expected := []string{CheckTitle, CheckH1, CheckLinkWarning, CheckNoindex, "img-alt"}
+	if ids := CheckIDs(); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected checks %v, got %v", expected, ids)
+	}
+	if c, ok := LookupCheck("img-alt"); !ok || c.Description() != "The images have a text alternative." {
+		t.Errorf("Expected the registered check, got %v", c)
+	}
+	if _, ok := LookupCheck("unknown"); ok {
+		t.Error("Expected no check with an unknown ID")
+	}
+
+	// A check with the ID of a registered check replaces it in place
+	replacement := NewElementCheck(CheckH1, "The page has h1 headings.", SeverityNote, nil, nil)
+	RegisterCheck(replacement)
+	if ids := CheckIDs(); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected checks %v, got %v", expected, ids)
+	}
+	if c, _ := LookupCheck(CheckH1); c.Description() != "The page has h1 headings." {
+		t.Errorf("Expected the replaced check, got %v", c)
+	}
+}
+
+func TestAnalyzeChecks(t *testing.T) {
+	registerChecks(t, imgAltCheck, statusCheck{})
+
+	pageURL := "http://example.com"
+	body := `<html><head><title>Home</title></head>
+				<body>
+					<h1>Home</h1>
+					<img src="logo.png" alt="Logo">
+					<img src="banner.png">
+					<a href="#">Top</a>
+				</body>
+			</html>`
+
+	tests := []struct {
+		name           string
+		disabled       []string
+		expectedChecks []string
+		expected       []Finding
+	}{
+		{
+			name:           "All checks",
+			expectedChecks: []string{CheckTitle, CheckH1, CheckLinkWarning, CheckNoindex, "img-alt", "status"},
+			expected: []Finding{
+				{Check: CheckLinkWarning, Severity: SeverityNote, Message: "The link only points to '#' and leads nowhere.", Target: "#"},
+				{Check: "img-alt", Severity: SeverityWarning, Message: "The image has no alt attribute.", Target: "banner.png"},
+				{Check: "status", Severity: SeverityWarning, Message: "Non-Authoritative Information"},
+			},
+		},
+		{
+			name:           "Disabled checks",
+			disabled:       []string{CheckLinkWarning, "status"},
+			expectedChecks: []string{CheckTitle, CheckH1, CheckNoindex, "img-alt"},
+			expected: []Finding{
+				{Check: "img-alt", Severity: SeverityWarning, Message: "The image has no alt attribute.", Target: "banner.png"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockUtils := mocks.NewMockUtilProvider(ctrl)
+			utilsInstance = mockUtils
+
+			page := newPage(pageURL, body)
+			page.StatusCode = http.StatusNonAuthoritativeInfo
+			doc, _ := html.Parse(strings.NewReader(body))
+
+			mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(page, nil)
+			mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+			mockUtils.EXPECT().ExtractHTMLVersion(gomock.Any()).Return("HTML 5")
+			mockUtils.EXPECT().ExtractTitle(gomock.Any()).Return("Home")
+			mockUtils.EXPECT().ExtractAttribute(gomock.Any(), "href").Return("#")
+			mockUtils.EXPECT().ExtractAttribute(gomock.Any(), "src").Return("banner.png")
+
+			res := (&Analyzer{DisabledChecks: test.disabled}).Analyze(pageURL)
+
+			if !reflect.DeepEqual(res.Checks, test.expectedChecks) {
+				t.Errorf("Expected checks %v, got %v", test.expectedChecks, res.Checks)
+			}
+			if !reflect.DeepEqual(res.Findings, test.expected) {
+				t.Errorf("Expected findings %+v, got %+v", test.expected, res.Findings)
+			}
+			// The link warnings of the result do not depend on the link-warning check
+			if len(res.LinkWarnings) != 1 {
+				t.Errorf("Expected the link warning of the page, got %v", res.LinkWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package analyzer
+
+// Severities of the findings, named after the levels of SARIF
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// Finding is an issue found on an analyzed page by a check
+type Finding struct {
+	// Check is the ID of the check that found the issue, eg. "broken-link"
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Target is the part of the page the issue is about, eg. the URL of a broken link. Empty
+	// when the issue is about the page itself.
+	Target string `json:"target,omitempty"`
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// LinkCache shares the link checks between the analyses of a crawl, so that a link found
+// on several pages is checked once and the pages fetched by the analyses are not checked
+// again. It is safe for concurrent use.
+type LinkCache struct {
+	normalize func(string) (string, error)
+
+	mu      sync.Mutex
+	entries map[string]*linkCacheEntry
+}
+
+// linkCacheEntry is a link check, done is closed once the check is complete
+type linkCacheEntry struct {
+	done  chan struct{}
+	check utils.LinkCheck
+	// ok is false if the check was aborted by the cancellation and is not cached
+	ok bool
+}
+
+// NewLinkCache returns an empty cache keyed by the links normalized with normalize. The
+// links that cannot be normalized are keyed as they are.
+func NewLinkCache(normalize func(string) (string, error)) *LinkCache {
+	return &LinkCache{normalize: normalize, entries: make(map[string]*linkCacheEntry)}
+}
+
+// linkCacheKey is the context key of the link cache of an analysis
+type linkCacheKey struct{}
+
+// WithLinkCache returns a copy of the context in which the analyses share the link cache
+func WithLinkCache(ctx context.Context, cache *LinkCache) context.Context {
+	return context.WithValue(ctx, linkCacheKey{}, cache)
+}
+
+// linkCacheFrom returns the link cache of the context, nil if there is none
+func linkCacheFrom(ctx context.Context) *LinkCache {
+	cache, _ := ctx.Value(linkCacheKey{}).(*LinkCache)
+	return cache
+}
+
+func (c *LinkCache) key(link string) string {
+	if c.normalize == nil {
+		return link
+	}
+	key, err := c.normalize(link)
+	if err != nil {
+		return link
+	}
+	return key
+}
+
+// check returns the cached check of the link, or checks it with checkFn. Concurrent checks
+// of the same link wait for the first one. The checks aborted by the cancellation of the
+// context are not cached.
+func (c *LinkCache) check(ctx context.Context, link string, checkFn func() (utils.LinkCheck, error)) (utils.LinkCheck, error) {
+	key := c.key(link)
+	for {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		if !ok {
+			entry = &linkCacheEntry{done: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			check, err := checkFn()
+			c.mu.Lock()
+			if err != nil || ctx.Err() != nil {
+				delete(c.entries, key)
+			} else {
+				entry.check, entry.ok = check, true
+			}
+			c.mu.Unlock()
+			close(entry.done)
+			return check, err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return utils.LinkCheck{}, ctx.Err()
+		}
+		if entry.ok {
+			// The cached check may be of another form of the link
+			check := entry.check
+			check.URL = link
+			return check, nil
+		}
+	}
+}
+
+// storePage caches the successful fetch of a page as the check of the URL it was requested
+// with and of the URL it landed on
+func (c *LinkCache) storePage(requestURL string, page *utils.Page) {
+	check := utils.LinkCheck{
+		Accessible: true,
+		State:      utils.LinkStateOK,
+		Method:     http.MethodGet,
+		StatusCode: page.StatusCode,
+		Redirects:  page.Redirects,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(requestURL, check)
+	if page.URL != "" && page.URL != requestURL {
+		check.Redirects = nil
+		c.store(page.URL, check)
+	}
+}
+
+// store caches the check of the link unless the link is already cached or being checked.
+// The caller must hold the lock.
+func (c *LinkCache) store(link string, check utils.LinkCheck) {
+	key := c.key(link)
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	done := make(chan struct{})
+	close(done)
+	c.entries[key] = &linkCacheEntry{done: done, check: check, ok: true}
+}
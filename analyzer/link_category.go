@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Categories of the links of a page by the scheme of their href. Only the http links are
+// classified as internal or external and checked.
+const (
+	// LinkCategoryHTTP links have an http or https URL, or a relative URL
+	LinkCategoryHTTP       = "http"
+	LinkCategoryMailto     = "mailto"
+	LinkCategoryTel        = "tel"
+	LinkCategoryJavaScript = "javascript"
+	LinkCategoryData       = "data"
+	// LinkCategoryFragment links point to a part of the page itself
+	LinkCategoryFragment = "fragment"
+	// LinkCategoryEmpty links have an empty href
+	LinkCategoryEmpty = "empty"
+	// LinkCategoryOther links have any other scheme, eg. ftp or a custom app scheme
+	LinkCategoryOther = "other"
+)
+
+// LinkWarning reports a link that does not lead anywhere for crawlers and assistive technologies
+type LinkWarning struct {
+	Href     string `json:"href"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// linkCategory returns the category of the href of a link
+func linkCategory(href string) string {
+	href = strings.TrimSpace(href)
+	switch {
+	case href == "":
+		return LinkCategoryEmpty
+	case strings.HasPrefix(href, "#"):
+		return LinkCategoryFragment
+	}
+
+	// Hrefs that cannot be parsed are kept with the http links and reported as inaccessible
+	u, err := url.Parse(href)
+	if err != nil {
+		return LinkCategoryHTTP
+	}
+
+	switch u.Scheme {
+	case "", "http", "https":
+		return LinkCategoryHTTP
+	case LinkCategoryMailto, LinkCategoryTel, LinkCategoryJavaScript, LinkCategoryData:
+		return u.Scheme
+	}
+	return LinkCategoryOther
+}
+
+// linkWarning returns why the link is a smell, or an empty string if it is not
+func linkWarning(href string, category string) string {
+	switch category {
+	case LinkCategoryJavaScript:
+		return "javascript: links cannot be followed by crawlers and are not announced as links by screen readers. Use a button instead."
+	case LinkCategoryEmpty:
+		return "The link has an empty href and leads nowhere."
+	case LinkCategoryFragment:
+		if strings.TrimSpace(href) == "#" {
+			return "The link only points to '#' and leads nowhere."
+		}
+	}
+	return ""
+}
+
+// hasAttribute checks whether the node has the attribute, even if its value is empty
+func hasAttribute(n *html.Node, attr string) bool {
+	for _, a := range n.Attr {
+		if a.Key == attr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestLinkCategory(t *testing.T) {
+	tests := []struct {
+		href     string
+		expected string
+	}{
+		{href: "https://example.com/page", expected: LinkCategoryHTTP},
+		{href: "HTTP://EXAMPLE.COM/", expected: LinkCategoryHTTP},
+		{href: "/relative/page", expected: LinkCategoryHTTP},
+		{href: "page.html?q=1", expected: LinkCategoryHTTP},
+		{href: "//cdn.example.com/lib.js", expected: LinkCategoryHTTP},
+		{href: "mailto:team@example.com", expected: LinkCategoryMailto},
+		{href: "tel:+15550100", expected: LinkCategoryTel},
+		{href: " javascript:void(0)", expected: LinkCategoryJavaScript},
+		{href: "data:text/plain,hello", expected: LinkCategoryData},
+		{href: "#section", expected: LinkCategoryFragment},
+		{href: "#", expected: LinkCategoryFragment},
+		{href: "  ", expected: LinkCategoryEmpty},
+		{href: "ftp://example.com/file", expected: LinkCategoryOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.href, func(t *testing.T) {
+			if category := linkCategory(test.href); category != test.expected {
+				t.Errorf("Expected category '%s', got '%s'", test.expected, category)
+			}
+		})
+	}
+}
+
+func TestLinkWarning(t *testing.T) {
+	tests := []struct {
+		href            string
+		expectedWarning bool
+	}{
+		{href: "javascript:void(0)", expectedWarning: true},
+		{href: "", expectedWarning: true},
+		{href: "#", expectedWarning: true},
+		{href: "#section"},
+		{href: "mailto:team@example.com"},
+		{href: "/page"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.href, func(t *testing.T) {
+			warning := linkWarning(test.href, linkCategory(test.href))
+			if (warning != "") != test.expectedWarning {
+				t.Errorf("Expected a warning %v, got '%s'", test.expectedWarning, warning)
+			}
+		})
+	}
+}
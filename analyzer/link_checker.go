@@ -0,0 +1,236 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// Default link check limits used for the zero values of the Analyzer fields
+const (
+	DefaultMaxConcurrency     = 20
+	DefaultPerHostConcurrency = 4
+)
+
+// hostLimiter limits the concurrency and the request rate of the link checks of a host
+type hostLimiter struct {
+	sem   chan struct{}
+	delay time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newHostLimiter(concurrency int, delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		sem:   make(chan struct{}, concurrency),
+		delay: delay,
+	}
+}
+
+// acquire blocks until a check of the host is allowed to start. release must be called
+// once the check is done if no error is returned.
+func (h *hostLimiter) acquire(ctx context.Context) error {
+	// select picks randomly among the ready cases, so a free slot could win over the cancellation
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case h.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if h.delay <= 0 {
+		return nil
+	}
+
+	// Reserve the next start slot of the host
+	h.mu.Lock()
+	start := time.Now()
+	if h.next.After(start) {
+		start = h.next
+	}
+	h.next = start.Add(h.delay)
+	h.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		h.release()
+		return ctx.Err()
+	}
+}
+
+func (h *hostLimiter) release() {
+	<-h.sem
+}
+
+// checkLinks checks the links on a bounded pool of workers and returns the report of each
+// checked link in the order of urlList. The links in internal are reported as internal. The
+// checks of the same host are additionally limited by PerHostConcurrency and spaced by
+// PerHostDelay. The links not checked because the context is canceled are left out of the
+// reports. When the context holds a link cache, the checks are shared with the other analyses
+// using it.
+func (a *Analyzer) checkLinks(ctx context.Context, urlList []string, internal map[string]bool, progress ProgressFunc) []LinkReport {
+	workers := a.MaxConcurrency
+	if workers <= 0 {
+		workers = DefaultMaxConcurrency
+	}
+	if workers > len(urlList) {
+		workers = len(urlList)
+	}
+
+	perHost := a.PerHostConcurrency
+	if perHost <= 0 {
+		perHost = DefaultPerHostConcurrency
+	}
+
+	// The limiters are created upfront so that the workers only read the map
+	links := interleaveByHost(urlList)
+	limiters := make(map[string]*hostLimiter)
+	for _, link := range links {
+		host := linkHost(link)
+		if _, ok := limiters[host]; !ok {
+			limiters[host] = newHostLimiter(perHost, a.PerHostDelay)
+		}
+	}
+
+	queue := make(chan string)
+	go func() {
+		defer close(queue)
+		for _, link := range links {
+			select {
+			case queue <- link:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	position := make(map[string]int, len(urlList))
+	for i, link := range urlList {
+		position[link] = i
+	}
+
+	var wg sync.WaitGroup
+	//mutex is used to lock the 'reports' when they are updated by multiple goroutines
+	//and to make sure the progress function is not called concurrently
+	var mu sync.Mutex
+	var checked int
+	reports := make([]*LinkReport, len(urlList))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for link := range queue {
+				check, err := checkLink(ctx, link, limiters[linkHost(link)])
+				if err != nil {
+					continue
+				}
+				report := LinkReport{LinkCheck: check, Internal: internal[link]}
+
+				mu.Lock()
+				// Checks aborted by the cancellation are not reported as inaccessible
+				if ctx.Err() == nil {
+					reports[position[link]] = &report
+					checked++
+					progress.emitLink(report, checked, len(urlList))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	res := make([]LinkReport, 0, checked)
+	for _, report := range reports {
+		if report != nil {
+			res = append(res, *report)
+		}
+	}
+	return res
+}
+
+// checkLink checks the link within the limits of its host, or returns its check from the
+// link cache of the context. An error is returned if the check is aborted before it starts.
+func checkLink(ctx context.Context, link string, limiter *hostLimiter) (utils.LinkCheck, error) {
+	checkFn := func() (utils.LinkCheck, error) {
+		if err := limiter.acquire(ctx); err != nil {
+			return utils.LinkCheck{}, err
+		}
+		defer limiter.release()
+		return utilsInstance.CheckLink(ctx, link), nil
+	}
+
+	if cache := linkCacheFrom(ctx); cache != nil {
+		return cache.check(ctx, link, checkFn)
+	}
+	return checkFn()
+}
+
+// countInaccessible returns the number of inaccessible internal and external links
+func countInaccessible(links []LinkReport) (internal int, external int) {
+	for _, link := range links {
+		switch {
+		case link.Accessible:
+		case link.Internal:
+			internal++
+		default:
+			external++
+		}
+	}
+	return internal, external
+}
+
+// interleaveByHost reorders the links so that consecutive links belong to different hosts
+// where possible. This keeps the workers busy with other hosts while a host is throttled.
+func interleaveByHost(links []string) []string {
+	var hosts []string
+	byHost := make(map[string][]string)
+	for _, link := range links {
+		host := linkHost(link)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], link)
+	}
+
+	res := make([]string, 0, len(links))
+	for len(res) < len(links) {
+		for _, host := range hosts {
+			if queue := byHost[host]; len(queue) > 0 {
+				res = append(res, queue[0])
+				byHost[host] = queue[1:]
+			}
+		}
+	}
+	return res
+}
+
+// linkHost returns the lower-cased host of the link, or an empty string if the link
+// cannot be parsed
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
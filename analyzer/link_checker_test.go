@@ -0,0 +1,273 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/utils"
+	"github.com/isurukdniss/webpage-analyzer/utils/mocks"
+)
+
+// concurrencyTracker records the maximum number of concurrent link checks, globally and per host
+type concurrencyTracker struct {
+	mu         sync.Mutex
+	inFlight   int
+	maxGlobal  int
+	perHost    map[string]int
+	maxPerHost int
+	starts     map[string][]time.Time
+}
+
+func newConcurrencyTracker() *concurrencyTracker {
+	return &concurrencyTracker{perHost: make(map[string]int), starts: make(map[string][]time.Time)}
+}
+
+func (c *concurrencyTracker) check(ctx context.Context, link string) utils.LinkCheck {
+	host := linkHost(link)
+
+	c.mu.Lock()
+	c.inFlight++
+	c.perHost[host]++
+	c.maxGlobal = max(c.maxGlobal, c.inFlight)
+	c.maxPerHost = max(c.maxPerHost, c.perHost[host])
+	c.starts[host] = append(c.starts[host], time.Now())
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.perHost[host]--
+	c.mu.Unlock()
+
+	return utils.LinkCheck{URL: link, Accessible: true}
+}
+
+func TestCheckLinksLimits(t *testing.T) {
+	tests := []struct {
+		name               string
+		hosts              int
+		linksPerHost       int
+		analyzer           *Analyzer
+		expectedMaxGlobal  int
+		expectedMaxPerHost int
+	}{
+		{
+			name:               "Global concurrency limit",
+			hosts:              10,
+			linksPerHost:       1,
+			analyzer:           &Analyzer{MaxConcurrency: 3},
+			expectedMaxGlobal:  3,
+			expectedMaxPerHost: 1,
+		},
+		{
+			name:               "Per-host concurrency limit",
+			hosts:              1,
+			linksPerHost:       10,
+			analyzer:           &Analyzer{MaxConcurrency: 10, PerHostConcurrency: 2},
+			expectedMaxGlobal:  2,
+			expectedMaxPerHost: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUtils := mocks.NewMockUtilProvider(ctrl)
+			utilsInstance = mockUtils
+
+			tracker := newConcurrencyTracker()
+			mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(tracker.check).Times(test.hosts * test.linksPerHost)
+
+			var links []string
+			for h := 0; h < test.hosts; h++ {
+				for l := 0; l < test.linksPerHost; l++ {
+					links = append(links, fmt.Sprintf("http://host%d.com/%d", h, l))
+				}
+			}
+
+			reports := test.analyzer.checkLinks(context.Background(), links, nil, nil)
+
+			if len(reports) != len(links) {
+				t.Fatalf("Expected %d link reports, got %d", len(links), len(reports))
+			}
+			for i, report := range reports {
+				if report.URL != links[i] {
+					t.Errorf("Expected report %d to be of '%s', got '%s'", i, links[i], report.URL)
+				}
+			}
+			if tracker.maxGlobal > test.expectedMaxGlobal {
+				t.Errorf("Expected at most %d concurrent checks, got %d", test.expectedMaxGlobal, tracker.maxGlobal)
+			}
+			if tracker.maxPerHost > test.expectedMaxPerHost {
+				t.Errorf("Expected at most %d concurrent checks per host, got %d", test.expectedMaxPerHost, tracker.maxPerHost)
+			}
+		})
+	}
+}
+
+func TestCheckLinksPerHostDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	tracker := newConcurrencyTracker()
+	mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(tracker.check).Times(3)
+
+	delay := 20 * time.Millisecond
+	a := &Analyzer{PerHostDelay: delay}
+	begin := time.Now()
+	a.checkLinks(context.Background(), []string{"http://a.com/1", "http://a.com/2", "http://a.com/3"}, nil, nil)
+
+	// The n-th check of the host must not start before n delays have passed. Comparing with
+	// the beginning of the run keeps the test stable when a start is recorded late.
+	starts := tracker.starts["a.com"]
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for i, start := range starts {
+		// Allow some scheduling jitter
+		if elapsed := start.Sub(begin); elapsed < time.Duration(i)*delay-2*time.Millisecond {
+			t.Errorf("Expected check %d of the same host to start after %s, got %s", i, time.Duration(i)*delay, elapsed)
+		}
+	}
+}
+
+func TestCheckLinksCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link string) utils.LinkCheck {
+		cancel()
+		return utils.LinkCheck{URL: link, ErrorClass: utils.ErrorClassNetwork}
+	}).MaxTimes(1)
+
+	a := &Analyzer{MaxConcurrency: 1, PerHostDelay: time.Hour}
+	reports := a.checkLinks(ctx, []string{"http://a.com/1", "http://a.com/2"}, nil, nil)
+
+	if len(reports) != 0 {
+		t.Errorf("Expected the canceled checks not to be reported, got %v", reports)
+	}
+}
+
+// normalizeTestLink normalizes the links of the link cache tests on their case and fragment
+func normalizeTestLink(link string) (string, error) {
+	link, _, _ = strings.Cut(strings.ToLower(link), "#")
+	return link, nil
+}
+
+func TestCheckLinksCache(t *testing.T) {
+	tests := []struct {
+		name            string
+		fetched         map[string]*utils.Page
+		analyses        [][]string
+		expectedChecked []string
+	}{
+		{
+			name:            "Links shared between analyses",
+			analyses:        [][]string{{"http://a.com/1", "http://a.com/2"}, {"http://A.com/1#top", "http://a.com/3"}},
+			expectedChecked: []string{"http://a.com/1", "http://a.com/2", "http://a.com/3"},
+		},
+		{
+			name: "Fetched pages not checked",
+			fetched: map[string]*utils.Page{
+				"http://a.com/": {URL: "http://a.com/home", StatusCode: 200},
+			},
+			analyses:        [][]string{{"http://a.com/", "http://a.com/home#top", "http://a.com/1"}},
+			expectedChecked: []string{"http://a.com/1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUtils := mocks.NewMockUtilProvider(ctrl)
+			utilsInstance = mockUtils
+
+			var mu sync.Mutex
+			var checked []string
+			mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link string) utils.LinkCheck {
+				mu.Lock()
+				checked = append(checked, link)
+				mu.Unlock()
+				return utils.LinkCheck{URL: link, Accessible: true, State: utils.LinkStateOK}
+			}).AnyTimes()
+
+			cache := NewLinkCache(normalizeTestLink)
+			for requestURL, page := range test.fetched {
+				cache.storePage(requestURL, page)
+			}
+			ctx := WithLinkCache(context.Background(), cache)
+
+			a := &Analyzer{}
+			for _, links := range test.analyses {
+				reports := a.checkLinks(ctx, links, nil, nil)
+				if len(reports) != len(links) {
+					t.Fatalf("Expected %d link reports, got %d", len(links), len(reports))
+				}
+				for i, report := range reports {
+					if report.URL != links[i] || !report.Accessible {
+						t.Errorf("Expected an accessible report of '%s', got %+v", links[i], report.LinkCheck)
+					}
+				}
+			}
+
+			sort.Strings(checked)
+			if !reflect.DeepEqual(checked, test.expectedChecked) {
+				t.Errorf("Expected the checks of %v, got %v", test.expectedChecked, checked)
+			}
+		})
+	}
+}
+
+func TestLinkCacheCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	cache := NewLinkCache(normalizeTestLink)
+	canceledCtx, cancel := context.WithCancel(WithLinkCache(context.Background(), cache))
+	mockUtils.EXPECT().CheckLink(gomock.Any(), "http://a.com/1").DoAndReturn(func(ctx context.Context, link string) utils.LinkCheck {
+		cancel()
+		return utils.LinkCheck{URL: link, ErrorClass: utils.ErrorClassNetwork}
+	})
+	mockUtils.EXPECT().CheckLink(gomock.Any(), "http://a.com/1").Return(utils.LinkCheck{URL: "http://a.com/1", Accessible: true})
+
+	a := &Analyzer{}
+	a.checkLinks(canceledCtx, []string{"http://a.com/1"}, nil, nil)
+	reports := a.checkLinks(WithLinkCache(context.Background(), cache), []string{"http://a.com/1"}, nil, nil)
+
+	// The check aborted by the cancellation is not cached
+	if len(reports) != 1 || !reports[0].Accessible {
+		t.Errorf("Expected the link to be checked again, got %v", reports)
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	links := []string{"http://a.com/1", "http://a.com/2", "http://a.com/3", "http://b.com/1", "http://c.com/1", "http://b.com/2"}
+	expected := []string{"http://a.com/1", "http://b.com/1", "http://c.com/1", "http://a.com/2", "http://b.com/2", "http://a.com/3"}
+
+	res := interleaveByHost(links)
+
+	if fmt.Sprint(res) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, res)
+	}
+}
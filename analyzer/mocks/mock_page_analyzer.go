@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	analyzer "github.com/isurukdniss/webpage-analyzer/analyzer"
@@ -52,3 +53,17 @@ func (mr *MockPageAnalyzerMockRecorder) Analyze(pageURL any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Analyze", reflect.TypeOf((*MockPageAnalyzer)(nil).Analyze), pageURL)
 }
+
+// AnalyzeContext mocks base method.
+func (m *MockPageAnalyzer) AnalyzeContext(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyzeContext", ctx, pageURL, progress)
+	ret0, _ := ret[0].(*analyzer.Result)
+	return ret0
+}
+
+// AnalyzeContext indicates an expected call of AnalyzeContext.
+func (mr *MockPageAnalyzerMockRecorder) AnalyzeContext(ctx, pageURL, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyzeContext", reflect.TypeOf((*MockPageAnalyzer)(nil).AnalyzeContext), ctx, pageURL, progress)
+}
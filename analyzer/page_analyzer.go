@@ -1,9 +1,13 @@
 package analyzer
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/isurukdniss/webpage-analyzer/utils"
 
@@ -12,60 +16,267 @@ import (
 
 var utilsInstance utils.UtilProvider = &utils.Utils{}
 
+// SetUtils sets the helpers used to fetch the pages and check the links
+func SetUtils(u utils.UtilProvider) {
+	utilsInstance = u
+}
+
 // Result represents the webpage analyzer output data structure
 type Result struct {
-	HTMLVersion        string
-	Title              string
-	HeadingsCount      map[string]int
-	InternalLinksCount int
-	ExternalLinksCount int
-	InAccessibleLinks  int
-	HasLoginForm       bool
-	ErrorMessage       string
-	ExternalLinks      []string
+	// FinalURL is the URL of the analyzed page after the redirects. The links are classified
+	// as internal or external against its host.
+	FinalURL  string           `json:"final_url"`
+	Redirects []utils.Redirect `json:"redirects,omitempty"`
+	// TransferredBytes and DecodedBytes are the size of the page body before and after the
+	// content decoding
+	TransferredBytes int64 `json:"transferred_bytes"`
+	DecodedBytes     int64 `json:"decoded_bytes"`
+	// RobotsDisallowed reports that the robots.txt of the site disallows the page
+	RobotsDisallowed bool `json:"robots_disallowed,omitempty"`
+	// StatusCode is the status code of the final response, zero if no response was received
+	StatusCode int `json:"status_code,omitempty"`
+	// Noindex reports that the X-Robots-Tag header or a robots meta tag asks not to index the page
+	Noindex bool `json:"noindex,omitempty"`
+	// Charset is the character encoding the page is decoded with before the analysis
+	Charset            utils.Charset  `json:"charset"`
+	HTMLVersion        string         `json:"html_version"`
+	Title              string         `json:"title"`
+	HeadingsCount      map[string]int `json:"headings_count"`
+	InternalLinksCount int            `json:"internal_links_count"`
+	ExternalLinksCount int            `json:"external_links_count"`
+	// InAccessibleLinks is the sum of the inaccessible internal and external links
+	InAccessibleLinks         int          `json:"inaccessible_links_count"`
+	InAccessibleInternalLinks int          `json:"inaccessible_internal_links_count"`
+	InAccessibleExternalLinks int          `json:"inaccessible_external_links_count"`
+	HasLoginForm              bool         `json:"has_login_form"`
+	ErrorCode                 string       `json:"error_code,omitempty"`
+	ErrorMessage              string       `json:"error_message,omitempty"`
+	InternalLinks             []string     `json:"internal_links"`
+	ExternalLinks             []string     `json:"external_links"`
+	Links                     []LinkReport `json:"links"`
+	// LinkCategories counts the links of the page by the category of their href, see the
+	// LinkCategory constants
+	LinkCategories map[string]int `json:"link_categories"`
+	// LinkWarnings reports the javascript: links and the links with an empty or "#" href
+	LinkWarnings []LinkWarning `json:"link_warnings,omitempty"`
+	// Checks are the IDs of the checks the page is checked against, and Findings the issues
+	// they found
+	Checks   []string  `json:"checks"`
+	Findings []Finding `json:"findings,omitempty"`
+	// Stages reports the outcome of each stage of the analysis pipeline in order
+	Stages []StageReport `json:"stages"`
+	// Errors lists the errors of the failed and interrupted stages. ErrorCode and ErrorMessage
+	// hold the first one.
+	Errors []StageError `json:"errors,omitempty"`
+}
+
+// LinkReport is the report of the accessibility check of a link found in the page
+type LinkReport struct {
+	utils.LinkCheck
+	Internal bool `json:"internal"`
 }
 
+// Machine-readable error codes set in Result.ErrorCode when the analysis fails
+const (
+	ErrCodeInvalidURL        = "invalid_url"
+	ErrCodeUnsupportedScheme = "unsupported_scheme"
+	ErrCodeDNSFailed         = "dns_failed"
+	ErrCodeTLSFailed         = "tls_failed"
+	ErrCodeBlockedAddress    = "blocked_address"
+	ErrCodeRobotsDisallowed  = "robots_disallowed"
+	ErrCodeFetchFailed       = "fetch_failed"
+	ErrCodeTooManyRedirects  = "too_many_redirects"
+	ErrCodeRedirectLoop      = "redirect_loop"
+	ErrCodeUpstreamStatus    = "upstream_status"
+	ErrCodeBodyTooLarge      = "body_too_large"
+	ErrCodeReadFailed        = "read_failed"
+	ErrCodeParseFailed       = "parse_failed"
+	ErrCodeInvalidSitemap    = "invalid_sitemap"
+	ErrCodeCanceled          = "canceled"
+	ErrCodeTimeout           = "timeout"
+	ErrCodeUnknown           = "unknown"
+)
+
 // PageAnalyzer defines the interface for analyzing a webpage based on its URL
 type PageAnalyzer interface {
 	Analyze(pageURL string) *Result
+	AnalyzeContext(ctx context.Context, pageURL string, progress ProgressFunc) *Result
 }
 
 // Analyzer provides function to analyze the HTML content of a given URL
-type Analyzer struct{}
+type Analyzer struct {
+	// Timeout limits the duration of a single analysis. Zero means no limit.
+	Timeout time.Duration
+	// MaxConcurrency limits the number of links checked at the same time.
+	// Zero means DefaultMaxConcurrency.
+	MaxConcurrency int
+	// PerHostConcurrency limits the number of links of the same host checked at the
+	// same time. Zero means DefaultPerHostConcurrency.
+	PerHostConcurrency int
+	// PerHostDelay is the minimum delay between the starts of two checks of the same host
+	PerHostDelay time.Duration
+	// DisabledChecks are the IDs of the registered checks that are not run
+	DisabledChecks []string
+}
 
 // Analyze function analyzes the HTML content of the website of a given URL
 func (a *Analyzer) Analyze(pageURL string) *Result {
+	return a.AnalyzeContext(context.Background(), pageURL, nil)
+}
+
+// AnalyzeContext analyzes the HTML content of the website of a given URL and reports
+// each phase of the analysis to the progress function. progress may be nil.
+// The analysis is a pipeline of stages: a failed fetch or parse skips the following stages.
+// When the context is canceled or the timeout expires, the outstanding requests are
+// aborted and the partial result is returned with the corresponding error code.
+func (a *Analyzer) AnalyzeContext(ctx context.Context, pageURL string, progress ProgressFunc) *Result {
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
+	}
+
+	enabled := a.enabledChecks()
 	res := &Result{
-		HeadingsCount: make(map[string]int),
+		HeadingsCount:  make(map[string]int),
+		LinkCategories: make(map[string]int),
+		Checks:         make([]string, len(enabled)),
+	}
+	for i, c := range enabled {
+		res.Checks[i] = c.ID()
 	}
 	// To track the visited links
 	visited := make(map[string]bool)
 
-	body, err := utilsInstance.FetchURL(pageURL)
-	if err != nil {
-		res.ErrorMessage = handleErrorMsg(err)
-	}
+	var page *utils.Page
+	var doc *html.Node
+	var checked *checkRun
+	stages := []stage{
+		{
+			phase: PhaseFetch,
+			run: func() (err error) {
+				page, err = utilsInstance.FetchPage(ctx, pageURL)
+				if page != nil {
+					res.Redirects = page.Redirects
+					res.RobotsDisallowed = page.RobotsDisallowed
+					res.StatusCode = page.StatusCode
+					res.Noindex = page.Noindex
+				}
+				if err != nil {
+					return err
+				}
+				if cache := linkCacheFrom(ctx); cache != nil {
+					cache.storePage(pageURL, page)
+				}
+				// The following stages work on the page we landed on
+				res.FinalURL = page.URL
+				res.Charset = page.Charset
+				pageURL = page.URL
+				return nil
+			},
+		},
+		{
+			// The body is tokenized as it is downloaded. The HTML version is read from the doctype
+			// at the start of the body, so it is known even if the rest of the body fails.
+			phase: PhaseParse,
+			run: func() (err error) {
+				defer page.Body.Close()
 
-	doc, err := utilsInstance.ParseHTML(body)
-	if err != nil {
-		res.ErrorMessage = handleErrorMsg(err)
+				head := &headWriter{max: htmlVersionHeadSize}
+				doc, err = utilsInstance.ParseHTML(io.TeeReader(page.Body, head))
+				res.HTMLVersion = utilsInstance.ExtractHTMLVersion(head.String())
+				res.TransferredBytes, res.DecodedBytes = page.TransferredBytes, page.DecodedBytes
+				return err
+			},
+		},
+		{
+			phase: PhaseLinkDiscovery,
+			run: func() error {
+				document := &Document{
+					Root:       doc,
+					URL:        pageURL,
+					BaseURL:    documentBaseURL(doc, pageURL),
+					StatusCode: page.StatusCode,
+					Redirects:  page.Redirects,
+					Charset:    page.Charset,
+					UserAgent:  page.UserAgent,
+				}
+				checked = newCheckRun(enabled, document)
+				analyzeDoc(doc, document, visited, res, checked)
+				document.Title, document.HeadingsCount, document.Noindex = res.Title, res.HeadingsCount, res.Noindex
+				document.LinkWarnings = res.LinkWarnings
+				return nil
+			},
+		},
+		{
+			phase: PhaseChecks,
+			run: func() error {
+				res.Findings = checked.findings()
+				return nil
+			},
+		},
+		{
+			// Interrupted link checks keep the reports of the links checked so far
+			phase:   PhaseLinkCheck,
+			partial: true,
+			run: func() error {
+				links := append(append([]string(nil), res.InternalLinks...), res.ExternalLinks...)
+				internal := make(map[string]bool, len(res.InternalLinks))
+				for _, link := range res.InternalLinks {
+					internal[link] = true
+				}
+				res.Links = a.checkLinks(ctx, links, internal, progress)
+				res.InAccessibleInternalLinks, res.InAccessibleExternalLinks = countInaccessible(res.Links)
+				res.InAccessibleLinks = res.InAccessibleInternalLinks + res.InAccessibleExternalLinks
+				return ctx.Err()
+			},
+		},
 	}
+	runStages(stages, res, progress)
 
-	res.HTMLVersion = utilsInstance.ExtractHTMLVersion(body)
+	progress.emit(PhaseDone, res)
+	return res
+}
 
-	analyzeDoc(doc, pageURL, visited, res)
+// documentBaseURL returns the URL the relative links of the document are resolved against,
+// which is the href of the first <base> element if there is one, or the page URL otherwise
+func documentBaseURL(doc *html.Node, pageURL string) string {
+	href := findBaseHref(doc)
+	if href == "" {
+		return pageURL
+	}
 
-	externalLinks := res.ExternalLinks
-	// Inaccessible links check is performed only for external links
-	inAccessibleLinksCount := getInaccessibleLinksCount(externalLinks)
-	res.InAccessibleLinks = inAccessibleLinksCount
+	baseURL, err := utilsInstance.ResolveURL(pageURL, href)
+	if err != nil {
+		return pageURL
+	}
+	return baseURL
+}
 
-	return res
+func findBaseHref(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type == html.ElementNode && n.Data == "base" {
+		if href := utilsInstance.ExtractAttribute(n, "href"); href != "" {
+			return href
+		}
+	}
 
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if href := findBaseHref(c); href != "" {
+			return href
+		}
+	}
+	return ""
 }
 
-func analyzeDoc(n *html.Node, baseURL string, visited map[string]bool, res *Result) {
+// analyzeDoc collects the data of the document and runs the element checks on its elements.
+// The links are resolved against the base URL of the document and classified as internal or
+// external by comparing them with its URL.
+func analyzeDoc(n *html.Node, doc *Document, visited map[string]bool, res *Result, checked *checkRun) {
 	if n.Type == html.ElementNode {
+		checked.visit(n)
 		switch n.Data {
 		case "title":
 			// Some webpage's html may contain multiple <title> tags. eg. <title> tag inside svg tags.
@@ -76,12 +287,35 @@ func analyzeDoc(n *html.Node, baseURL string, visited map[string]bool, res *Resu
 			res.HeadingsCount[n.Data]++
 		case "input":
 			res.HasLoginForm = utilsInstance.HasLoginForm(n)
+		case "meta":
+			if isNoindexMeta(n, doc.UserAgent) {
+				res.Noindex = true
+			}
 		case "a":
+			// Anchors without an href are placeholders rather than links
+			if !hasAttribute(n, "href") {
+				break
+			}
 			link := utilsInstance.ExtractAttribute(n, "href")
+
+			category := linkCategory(link)
+			res.LinkCategories[category]++
+			if msg := linkWarning(link, category); msg != "" {
+				res.LinkWarnings = append(res.LinkWarnings, LinkWarning{Href: link, Category: category, Message: msg})
+			}
+			if category != LinkCategoryHTTP {
+				break
+			}
+
+			// Links that cannot be resolved are kept as they are and reported as inaccessible
+			if resolved, err := utilsInstance.ResolveURL(doc.BaseURL, link); err == nil {
+				link = resolved
+			}
 			if !visited[link] {
 				visited[link] = true
-				if isInternal := utilsInstance.IsInternalLink(baseURL, link); isInternal {
+				if isInternal := utilsInstance.IsInternalLink(doc.URL, link); isInternal {
 					res.InternalLinksCount++
+					res.InternalLinks = append(res.InternalLinks, link)
 				} else {
 					res.ExternalLinksCount++
 					res.ExternalLinks = append(res.ExternalLinks, link)
@@ -91,49 +325,131 @@ func analyzeDoc(n *html.Node, baseURL string, visited map[string]bool, res *Resu
 	}
 
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		analyzeDoc(c, baseURL, visited, res)
+		analyzeDoc(c, doc, visited, res, checked)
 	}
 }
 
-func getInaccessibleLinksCount(urlList []string) int {
-	var wg sync.WaitGroup
-	//mutex is used to lock the 'count' when it is updated by multiple goroutines
-	var mu sync.Mutex
-	var count int
-
-	for _, link := range urlList {
-		wg.Add(1)
-
-		go func(link string) {
-			defer wg.Done()
-			if !utilsInstance.IsLinkAccessible(link) {
-				mu.Lock()
-				count++
-				mu.Unlock()
-			}
-		}(link)
+// isNoindexMeta reports whether the element is a robots meta tag asking not to index the page,
+// eg. <meta name="robots" content="noindex">, for the user agent the page was requested with
+func isNoindexMeta(n *html.Node, userAgent string) bool {
+	var name, content string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "name":
+			name = a.Val
+		case "content":
+			content = a.Val
+		}
 	}
-	wg.Wait()
-	return count
+	return strings.EqualFold(strings.TrimSpace(name), "robots") && utils.HasNoindex(content, userAgent)
 }
 
+// handleErrorMsg returns the message shown to the user for the error of a failed analysis
 func handleErrorMsg(err error) string {
-	if strings.Contains(err.Error(), "invalid URI for request") {
-		return "The provided URL is not valid. Please check the format and try again."
-	}
-	if err.Error() == "invalid URL: missing scheme or host" {
+	var statusErr *utils.StatusError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "The analysis was canceled."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The analysis took too long and was stopped. Please try again later."
+	case errors.Is(err, utils.ErrMissingSchemeOrHost):
 		return "The URL is missing a scheme (like 'http' or 'https') or a host. Please provide a complete URL."
-	}
-	if err.Error() == "unable to fetch the URL" {
+	case errors.Is(err, utils.ErrUnsupportedScheme):
+		return "Only http and https URLs can be analyzed."
+	case errors.Is(err, utils.ErrInvalidURL):
+		return "The provided URL is not valid. Please check the format and try again."
+	case errors.Is(err, utils.ErrDNS):
+		return "The host of the URL could not be found. Please check the URL."
+	case errors.Is(err, utils.ErrTimeout):
+		return "The server took too long to respond. Please try again later."
+	case errors.Is(err, utils.ErrBlockedAddress):
+		return "The URL points to a private or reserved network address, which is not allowed to be analyzed."
+	case errors.Is(err, utils.ErrDisallowedByRobots):
+		return "The robots.txt of the site does not allow the URL to be requested."
+	case errors.Is(err, utils.ErrTLS):
+		return "A secure connection to the server could not be established. Please check the certificate of the server."
+	case errors.Is(err, utils.ErrTooManyRedirects):
+		return "The page redirected too many times."
+	case errors.Is(err, utils.ErrRedirectLoop):
+		return "The page redirects in a loop."
+	case errors.Is(err, utils.ErrFetchFailed):
 		return "We were unable to fetch the requested URL. Please check your internet connection or the URL."
-	}
-	if strings.Contains(err.Error(), "status code") {
-		split := strings.Split(err.Error(), " ")
-		sc := split[len(split)-1]
-		return fmt.Sprintf("The server returned a status code of %s. Please ensure you have the necessary permissions.", sc)
-	}
-	if err.Error() == "error reading the response body" {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("The server returned a status code of %d. Please ensure you have the necessary permissions.", statusErr.StatusCode)
+	case errors.Is(err, utils.ErrBodyTooLarge):
+		return "The page is too large to be analyzed."
+	case errors.Is(err, utils.ErrReadBody):
 		return "An error occurred while reading the response. Please try again later."
+	case errors.Is(err, utils.ErrParse):
+		return "The page could not be parsed as HTML."
+	case errors.Is(err, utils.ErrInvalidSitemap):
+		return "The sitemap is not a valid sitemap or sitemap index."
 	}
 	return "An unexpected error occurred. Please try again."
 }
+
+// handleErrorCode returns the machine-readable code of the error of a failed analysis
+func handleErrorCode(err error) string {
+	var statusErr *utils.StatusError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, utils.ErrTimeout):
+		return ErrCodeTimeout
+	case errors.Is(err, utils.ErrUnsupportedScheme):
+		return ErrCodeUnsupportedScheme
+	case errors.Is(err, utils.ErrInvalidURL):
+		return ErrCodeInvalidURL
+	case errors.Is(err, utils.ErrDNS):
+		return ErrCodeDNSFailed
+	case errors.Is(err, utils.ErrTLS):
+		return ErrCodeTLSFailed
+	case errors.Is(err, utils.ErrBlockedAddress):
+		return ErrCodeBlockedAddress
+	case errors.Is(err, utils.ErrDisallowedByRobots):
+		return ErrCodeRobotsDisallowed
+	case errors.Is(err, utils.ErrTooManyRedirects):
+		return ErrCodeTooManyRedirects
+	case errors.Is(err, utils.ErrRedirectLoop):
+		return ErrCodeRedirectLoop
+	case errors.Is(err, utils.ErrFetchFailed):
+		return ErrCodeFetchFailed
+	case errors.As(err, &statusErr):
+		return ErrCodeUpstreamStatus
+	case errors.Is(err, utils.ErrBodyTooLarge):
+		return ErrCodeBodyTooLarge
+	case errors.Is(err, utils.ErrReadBody):
+		return ErrCodeReadFailed
+	case errors.Is(err, utils.ErrParse):
+		return ErrCodeParseFailed
+	case errors.Is(err, utils.ErrInvalidSitemap):
+		return ErrCodeInvalidSitemap
+	}
+	return ErrCodeUnknown
+}
+
+// DescribeError returns the machine-readable code and the message shown to the user for an
+// error returned by the helpers outside of an analysis, eg. a sitemap fetch
+func DescribeError(err error) (code string, message string) {
+	return handleErrorCode(err), handleErrorMsg(err)
+}
+
+// htmlVersionHeadSize is the size of the start of the body the HTML version is read from
+const htmlVersionHeadSize = 1024
+
+// headWriter keeps the first bytes written to it, up to max
+type headWriter struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (h *headWriter) Write(p []byte) (int, error) {
+	if remaining := h.max - h.buf.Len(); remaining > 0 {
+		h.buf.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+func (h *headWriter) String() string {
+	return h.buf.String()
+}
@@ -1,18 +1,37 @@
 package analyzer
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	gomock "go.uber.org/mock/gomock"
 	"golang.org/x/net/html"
 
+	"github.com/isurukdniss/webpage-analyzer/utils"
 	"github.com/isurukdniss/webpage-analyzer/utils/mocks"
 )
 
 var pageAnalyzer PageAnalyzer = &Analyzer{}
 
+// newPage returns a fetched page with the given body
+func newPage(pageURL string, body string) *utils.Page {
+	return &utils.Page{URL: pageURL, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+// parsed returns a ParseHTML mock that reads the whole body as the parser does
+func parsed(doc *html.Node, err error) func(r io.Reader) (*html.Node, error) {
+	return func(r io.Reader) (*html.Node, error) {
+		io.Copy(io.Discard, r)
+		return doc, err
+	}
+}
+
 func TestAnalyze(t *testing.T) {
 	// mock the utils package
 	ctrl := gomock.NewController(t)
@@ -40,13 +59,14 @@ func TestAnalyze(t *testing.T) {
 	expectedInaccessibleLinksCount := 0
 
 	// setup mocks
-	mockUtils.EXPECT().FetchURL(pageURL).Return(body, nil)
-	mockUtils.EXPECT().ParseHTML(body).Return(doc, nil)
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
 	mockUtils.EXPECT().ExtractHTMLVersion(body).Return(expectedHTMLVersion)
 	mockUtils.EXPECT().ExtractTitle(gomock.Any()).Return(expectedTitle).Times(1)
 	mockUtils.EXPECT().ExtractAttribute(gomock.Any(), "href").Return("http://test.com").Times(1)
+	mockUtils.EXPECT().ResolveURL(pageURL, "http://test.com").Return("http://test.com", nil).Times(1)
 	mockUtils.EXPECT().IsInternalLink(pageURL, "http://test.com").Return(false).Times(1)
-	mockUtils.EXPECT().IsLinkAccessible(gomock.Any()).Return(true)
+	mockUtils.EXPECT().CheckLink(gomock.Any(), "http://test.com").Return(utils.LinkCheck{URL: "http://test.com", Accessible: true, StatusCode: 200})
 
 	res := pageAnalyzer.Analyze(pageURL)
 
@@ -65,7 +85,211 @@ func TestAnalyze(t *testing.T) {
 	if res.InternalLinksCount != expectedInaccessibleLinksCount {
 		t.Errorf("Expected inaccessible links count %d, got %d", expectedInaccessibleLinksCount, res.InternalLinksCount)
 	}
+	if len(res.Links) != 1 || res.Links[0].URL != "http://test.com" || res.Links[0].StatusCode != 200 {
+		t.Errorf("Expected the report of the checked link, got %v", res.Links)
+	}
+
+}
+
+func TestAnalyzeInternalLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	pageURL := "http://example.com/docs/page.html"
+	body := `<html>
+				<head><base href="/guide/"></head>
+				<body>
+					<a href="intro.html">Intro</a>
+					<a href="/guide/intro.html#setup">Intro again</a>
+					<a href="http://example.com/missing">Missing</a>
+					<a href="http://other.com/">Other</a>
+				</body>
+			</html>`
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	// The document is analyzed with the real helpers, only the network calls are mocked
+	helpers := &utils.Utils{}
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+	mockUtils.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+	mockUtils.EXPECT().ExtractAttribute(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ExtractAttribute).AnyTimes()
+	mockUtils.EXPECT().ResolveURL(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ResolveURL).AnyTimes()
+	mockUtils.EXPECT().IsInternalLink(gomock.Any(), gomock.Any()).DoAndReturn(helpers.IsInternalLink).AnyTimes()
+	mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link string) utils.LinkCheck {
+		return utils.LinkCheck{URL: link, Accessible: link != "http://example.com/missing"}
+	}).Times(3)
+
+	res := pageAnalyzer.Analyze(pageURL)
+
+	expectedInternalLinks := []string{"http://example.com/guide/intro.html", "http://example.com/missing"}
+	if fmt.Sprint(res.InternalLinks) != fmt.Sprint(expectedInternalLinks) {
+		t.Errorf("Expected internal links %v, got %v", expectedInternalLinks, res.InternalLinks)
+	}
+	if res.InternalLinksCount != 2 || res.ExternalLinksCount != 1 {
+		t.Errorf("Expected 2 internal and 1 external links, got %d and %d", res.InternalLinksCount, res.ExternalLinksCount)
+	}
+	if res.InAccessibleInternalLinks != 1 || res.InAccessibleExternalLinks != 0 || res.InAccessibleLinks != 1 {
+		t.Errorf("Expected 1 inaccessible internal link, got %d internal, %d external and %d in total",
+			res.InAccessibleInternalLinks, res.InAccessibleExternalLinks, res.InAccessibleLinks)
+	}
+	for _, link := range res.Links {
+		if link.Internal != (link.URL != "http://other.com/") {
+			t.Errorf("Expected '%s' to be reported as internal %v", link.URL, !link.Internal)
+		}
+	}
+}
 
+func TestAnalyzeRedirectedPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	pageURL := "http://example.com"
+	finalURL := "https://www.example.com/home"
+	redirects := []utils.Redirect{{URL: pageURL, StatusCode: http.StatusMovedPermanently, Location: finalURL}}
+	charset := utils.Charset{Name: "shift_jis", Source: utils.CharsetSourceHeader}
+	body := `<html><body><a href="/about">About</a><a href="http://example.com/old">Old</a></body></html>`
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	helpers := &utils.Utils{}
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(&utils.Page{URL: finalURL, Body: io.NopCloser(strings.NewReader(body)), Redirects: redirects, Charset: charset}, nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+	mockUtils.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+	mockUtils.EXPECT().ExtractAttribute(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ExtractAttribute).AnyTimes()
+	mockUtils.EXPECT().ResolveURL(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ResolveURL).AnyTimes()
+	mockUtils.EXPECT().IsInternalLink(finalURL, gomock.Any()).DoAndReturn(helpers.IsInternalLink).Times(2)
+	mockUtils.EXPECT().CheckLink(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link string) utils.LinkCheck {
+		return utils.LinkCheck{URL: link, Accessible: true}
+	}).Times(2)
+
+	res := pageAnalyzer.Analyze(pageURL)
+
+	if res.FinalURL != finalURL {
+		t.Errorf("Expected final URL '%s', got '%s'", finalURL, res.FinalURL)
+	}
+	if len(res.Redirects) != 1 || res.Redirects[0] != redirects[0] {
+		t.Errorf("Expected redirects %v, got %v", redirects, res.Redirects)
+	}
+	if res.Charset.Name != charset.Name || res.Charset.Source != charset.Source {
+		t.Errorf("Expected charset %v, got %v", charset, res.Charset)
+	}
+	// The links are resolved and classified against the host the page was served from
+	if fmt.Sprint(res.InternalLinks) != "[https://www.example.com/about]" {
+		t.Errorf("Expected internal links [https://www.example.com/about], got %v", res.InternalLinks)
+	}
+	if fmt.Sprint(res.ExternalLinks) != "[http://example.com/old]" {
+		t.Errorf("Expected external links [http://example.com/old], got %v", res.ExternalLinks)
+	}
+}
+
+func TestAnalyzeLinkCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	pageURL := "http://example.com"
+	body := `<html><body>
+				<a href="/about">About</a>
+				<a href="mailto:team@example.com">Mail</a>
+				<a href="tel:+15550100">Call</a>
+				<a href="javascript:void(0)">Menu</a>
+				<a href="#top">Top</a>
+				<a href="#">Nowhere</a>
+				<a href="">Empty</a>
+				<a name="anchor">Placeholder</a>
+				<a href="data:text/plain,hello">Data</a>
+				<a href="ftp://example.com/file">File</a>
+			</body></html>`
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	helpers := &utils.Utils{}
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+	mockUtils.EXPECT().ExtractHTMLVersion(gomock.Any()).Return("Unknown")
+	mockUtils.EXPECT().ExtractAttribute(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ExtractAttribute).AnyTimes()
+	mockUtils.EXPECT().ResolveURL(gomock.Any(), gomock.Any()).DoAndReturn(helpers.ResolveURL).AnyTimes()
+	mockUtils.EXPECT().IsInternalLink(pageURL, "http://example.com/about").Return(true)
+	// Only the http link is checked
+	mockUtils.EXPECT().CheckLink(gomock.Any(), "http://example.com/about").Return(utils.LinkCheck{URL: "http://example.com/about", Accessible: true})
+
+	res := pageAnalyzer.Analyze(pageURL)
+
+	expectedCategories := map[string]int{
+		LinkCategoryHTTP:       1,
+		LinkCategoryMailto:     1,
+		LinkCategoryTel:        1,
+		LinkCategoryJavaScript: 1,
+		LinkCategoryFragment:   2,
+		LinkCategoryEmpty:      1,
+		LinkCategoryData:       1,
+		LinkCategoryOther:      1,
+	}
+	if fmt.Sprint(res.LinkCategories) != fmt.Sprint(expectedCategories) {
+		t.Errorf("Expected link categories %v, got %v", expectedCategories, res.LinkCategories)
+	}
+	if res.InternalLinksCount != 1 || res.ExternalLinksCount != 0 {
+		t.Errorf("Expected only the http link to be classified, got %d internal and %d external", res.InternalLinksCount, res.ExternalLinksCount)
+	}
+
+	expectedWarnings := []string{"javascript:void(0)", "#", ""}
+	if len(res.LinkWarnings) != len(expectedWarnings) {
+		t.Fatalf("Expected warnings for %q, got %v", expectedWarnings, res.LinkWarnings)
+	}
+	for i, warning := range res.LinkWarnings {
+		if warning.Href != expectedWarnings[i] {
+			t.Errorf("Expected a warning for '%s', got '%s'", expectedWarnings[i], warning.Href)
+		}
+	}
+}
+
+func TestAnalyzeNoindex(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		noindex  bool
+		expected bool
+	}{
+		{name: "Indexed page", body: `<html><head><meta name="description" content="noindex"></head></html>`, expected: false},
+		{name: "Robots meta tag", body: `<html><head><meta name="ROBOTS" content="noindex, nofollow"></head></html>`, expected: true},
+		{name: "Robots meta tag of another crawler", body: `<html><head><meta name="robots" content="otherbot: noindex"></head></html>`, expected: false},
+		{name: "Robots meta tag of the configured user agent", body: `<html><head><meta name="robots" content="nightly-report: noindex"></head></html>`, expected: true},
+		{name: "X-Robots-Tag header", body: `<html></html>`, noindex: true, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockUtils := mocks.NewMockUtilProvider(ctrl)
+			utilsInstance = mockUtils
+
+			pageURL := "http://example.com"
+			page := newPage(pageURL, test.body)
+			page.StatusCode = http.StatusOK
+			page.Noindex = test.noindex
+			page.UserAgent = "nightly-report/2.0"
+			doc, _ := html.Parse(strings.NewReader(test.body))
+
+			mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(page, nil)
+			mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+			mockUtils.EXPECT().ExtractHTMLVersion(gomock.Any()).Return("HTML 5")
+
+			res := pageAnalyzer.Analyze(pageURL)
+
+			if res.Noindex != test.expected {
+				t.Errorf("Expected noindex %t, got %t", test.expected, res.Noindex)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, res.StatusCode)
+			}
+		})
+	}
 }
 
 func TestHandleErrorMsg(t *testing.T) {
@@ -74,25 +298,33 @@ func TestHandleErrorMsg(t *testing.T) {
 		expected string
 	}{
 		{
-			err:      errors.New("invalid URI for request"),
+			err:      fmt.Errorf("%w: parse \"abc\": invalid URI for request", utils.ErrInvalidURL),
 			expected: "The provided URL is not valid. Please check the format and try again.",
 		},
 		{
-			err:      errors.New("invalid URL: missing scheme or host"),
+			err:      utils.ErrMissingSchemeOrHost,
 			expected: "The URL is missing a scheme (like 'http' or 'https') or a host. Please provide a complete URL.",
 		},
 		{
-			err:      errors.New("unable to fetch the URL"),
+			err:      fmt.Errorf("%w: connection reset by peer", utils.ErrFetchFailed),
 			expected: "We were unable to fetch the requested URL. Please check your internet connection or the URL.",
 		},
 		{
-			err:      errors.New("server returned status code 404"),
+			err:      fmt.Errorf("%w: no such host", utils.ErrDNS),
+			expected: "The host of the URL could not be found. Please check the URL.",
+		},
+		{
+			err:      &utils.StatusError{StatusCode: 404},
 			expected: "The server returned a status code of 404. Please ensure you have the necessary permissions.",
 		},
 		{
-			err:      errors.New("error reading the response body"),
+			err:      fmt.Errorf("%w: unexpected EOF", utils.ErrReadBody),
 			expected: "An error occurred while reading the response. Please try again later.",
 		},
+		{
+			err:      utils.ErrBodyTooLarge,
+			expected: "The page is too large to be analyzed.",
+		},
 		{
 			err:      errors.New("some unexpected error"),
 			expected: "An unexpected error occurred. Please try again.",
@@ -108,3 +340,301 @@ func TestHandleErrorMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleErrorCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{
+			err:      fmt.Errorf("%w: parse \"abc\": invalid URI for request", utils.ErrInvalidURL),
+			expected: ErrCodeInvalidURL,
+		},
+		{
+			err:      utils.ErrMissingSchemeOrHost,
+			expected: ErrCodeInvalidURL,
+		},
+		{
+			err:      fmt.Errorf("%w \"ftp\"", utils.ErrUnsupportedScheme),
+			expected: ErrCodeUnsupportedScheme,
+		},
+		{
+			err:      fmt.Errorf("%w: no such host", utils.ErrDNS),
+			expected: ErrCodeDNSFailed,
+		},
+		{
+			err:      fmt.Errorf("%w: certificate signed by unknown authority", utils.ErrTLS),
+			expected: ErrCodeTLSFailed,
+		},
+		{
+			err:      fmt.Errorf("%w: i/o timeout", utils.ErrTimeout),
+			expected: ErrCodeTimeout,
+		},
+		{
+			err:      fmt.Errorf("dial tcp: %w: metadata resolves to 169.254.169.254", utils.ErrBlockedAddress),
+			expected: ErrCodeBlockedAddress,
+		},
+		{
+			err:      fmt.Errorf("%w: http://example.com/private", utils.ErrDisallowedByRobots),
+			expected: ErrCodeRobotsDisallowed,
+		},
+		{
+			err:      fmt.Errorf("%w: connection reset by peer", utils.ErrFetchFailed),
+			expected: ErrCodeFetchFailed,
+		},
+		{
+			err:      fmt.Errorf("Get \"http://example.com\": %w: stopped after 10 redirects", utils.ErrTooManyRedirects),
+			expected: ErrCodeTooManyRedirects,
+		},
+		{
+			err:      fmt.Errorf("Get \"http://example.com\": %w: http://example.com was already visited", utils.ErrRedirectLoop),
+			expected: ErrCodeRedirectLoop,
+		},
+		{
+			err:      &utils.StatusError{StatusCode: 404},
+			expected: ErrCodeUpstreamStatus,
+		},
+		{
+			err:      fmt.Errorf("%w: unexpected EOF", utils.ErrReadBody),
+			expected: ErrCodeReadFailed,
+		},
+		{
+			err:      utils.ErrBodyTooLarge,
+			expected: ErrCodeBodyTooLarge,
+		},
+		{
+			err:      fmt.Errorf("%w: unexpected token", utils.ErrParse),
+			expected: ErrCodeParseFailed,
+		},
+		{
+			err:      fmt.Errorf("%w: unexpected root element <html>", utils.ErrInvalidSitemap),
+			expected: ErrCodeInvalidSitemap,
+		},
+		{
+			err:      context.Canceled,
+			expected: ErrCodeCanceled,
+		},
+		{
+			err:      context.DeadlineExceeded,
+			expected: ErrCodeTimeout,
+		},
+		{
+			err:      errors.New("some unexpected error"),
+			expected: ErrCodeUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			result := handleErrorCode(test.err)
+			if result != test.expected {
+				t.Errorf("Expected error code %q, got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	pageURL := "http://example.com"
+	body := "<html><head><title>Test Page</title></head><body></body></html>"
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+	mockUtils.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+	mockUtils.EXPECT().ExtractTitle(gomock.Any()).Return("Test Page")
+
+	var phases []Phase
+	var last *Result
+	res := pageAnalyzer.AnalyzeContext(context.Background(), pageURL, func(ev Event) {
+		phases = append(phases, ev.Phase)
+		last = ev.Result
+	})
+
+	expectedPhases := []Phase{PhaseFetch, PhaseParse, PhaseLinkDiscovery, PhaseChecks, PhaseLinkCheck, PhaseDone}
+	if len(phases) != len(expectedPhases) {
+		t.Fatalf("Expected phases %v, got %v", expectedPhases, phases)
+	}
+	for i := range expectedPhases {
+		if phases[i] != expectedPhases[i] {
+			t.Errorf("Expected phase '%s' at %d, got '%s'", expectedPhases[i], i, phases[i])
+		}
+	}
+
+	if last == res {
+		t.Error("Expected the progress events to carry a snapshot of the result")
+	}
+	if last.Title != res.Title {
+		t.Errorf("Expected snapshot title '%s', got '%s'", res.Title, last.Title)
+	}
+}
+
+func TestAnalyzeCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	pageURL := "http://example.com"
+	body := `<html><body><a href="http://test.com">Link</a></body></html>`
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockUtils.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+	mockUtils.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+	mockUtils.EXPECT().ExtractHTMLVersion(body).Return("Unknown")
+	mockUtils.EXPECT().ExtractAttribute(gomock.Any(), "href").Return("http://test.com")
+	mockUtils.EXPECT().ResolveURL(pageURL, "http://test.com").Return("http://test.com", nil)
+	mockUtils.EXPECT().IsInternalLink(pageURL, "http://test.com").DoAndReturn(func(baseURL, targetURL string) bool {
+		// The user leaves before the links are checked
+		cancel()
+		return false
+	})
+
+	res := pageAnalyzer.AnalyzeContext(ctx, pageURL, nil)
+
+	if res.ErrorCode != ErrCodeCanceled {
+		t.Errorf("Expected error code '%s', got '%s'", ErrCodeCanceled, res.ErrorCode)
+	}
+	if res.InAccessibleLinks != 0 {
+		t.Errorf("Expected the unchecked links not to be reported as inaccessible, got %d", res.InAccessibleLinks)
+	}
+	if res.ExternalLinksCount != 1 {
+		t.Errorf("Expected the partial result to keep the discovered links, got %d", res.ExternalLinksCount)
+	}
+	if n := len(res.Stages); n == 0 || res.Stages[n-1].Stage != PhaseLinkCheck || res.Stages[n-1].Status != StagePartial {
+		t.Errorf("Expected the link check stage to be partial, got %v", res.Stages)
+	}
+}
+
+func TestAnalyzeTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	mockUtils.EXPECT().FetchPage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, url string) (*utils.Page, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected the analysis context to have a deadline")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	a := &Analyzer{Timeout: 10 * time.Millisecond}
+	res := a.AnalyzeContext(context.Background(), "http://example.com", nil)
+
+	if res.ErrorCode != ErrCodeTimeout {
+		t.Errorf("Expected error code '%s', got '%s'", ErrCodeTimeout, res.ErrorCode)
+	}
+}
+
+func TestAnalyzeStages(t *testing.T) {
+	pageURL := "http://example.com"
+	body := "<!DOCTYPE html><html><head><title>Test Page</title></head><body></body></html>"
+	doc, _ := html.Parse(strings.NewReader(body))
+
+	tests := []struct {
+		name           string
+		setup          func(m *mocks.MockUtilProvider)
+		expectedStages []string
+		expectedCode   string
+		expectedErrors []Phase
+	}{
+		{
+			name: "All stages succeed",
+			setup: func(m *mocks.MockUtilProvider) {
+				m.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+				m.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+				m.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(doc, nil))
+				m.EXPECT().ExtractTitle(gomock.Any()).Return("Test Page")
+			},
+			expectedStages: []string{StageOK, StageOK, StageOK, StageOK, StageOK},
+		},
+		{
+			name: "Fetch failure skips the following stages",
+			setup: func(m *mocks.MockUtilProvider) {
+				m.EXPECT().FetchPage(gomock.Any(), pageURL).Return(nil, &utils.StatusError{StatusCode: 404})
+			},
+			expectedStages: []string{StageFailed, StageSkipped, StageSkipped, StageSkipped, StageSkipped},
+			expectedCode:   ErrCodeUpstreamStatus,
+			expectedErrors: []Phase{PhaseFetch},
+		},
+		{
+			name: "Page disallowed by robots.txt",
+			setup: func(m *mocks.MockUtilProvider) {
+				page := &utils.Page{URL: pageURL, RobotsDisallowed: true}
+				m.EXPECT().FetchPage(gomock.Any(), pageURL).Return(page, fmt.Errorf("%w: %s", utils.ErrDisallowedByRobots, pageURL))
+			},
+			expectedStages: []string{StageFailed, StageSkipped, StageSkipped, StageSkipped, StageSkipped},
+			expectedCode:   ErrCodeRobotsDisallowed,
+			expectedErrors: []Phase{PhaseFetch},
+		},
+		{
+			name: "Parse failure skips the link stages",
+			setup: func(m *mocks.MockUtilProvider) {
+				m.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+				m.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+				m.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(nil, fmt.Errorf("%w: unexpected EOF", utils.ErrParse)))
+			},
+			expectedStages: []string{StageOK, StageFailed, StageSkipped, StageSkipped, StageSkipped},
+			expectedCode:   ErrCodeParseFailed,
+			expectedErrors: []Phase{PhaseParse},
+		},
+		{
+			name: "Body too large while streaming",
+			setup: func(m *mocks.MockUtilProvider) {
+				m.EXPECT().FetchPage(gomock.Any(), pageURL).Return(newPage(pageURL, body), nil)
+				m.EXPECT().ExtractHTMLVersion(body).Return("HTML 5")
+				m.EXPECT().ParseHTML(gomock.Any()).DoAndReturn(parsed(nil, fmt.Errorf("%w: %w: more than 100 bytes", utils.ErrParse, utils.ErrBodyTooLarge)))
+			},
+			expectedStages: []string{StageOK, StageFailed, StageSkipped, StageSkipped, StageSkipped},
+			expectedCode:   ErrCodeBodyTooLarge,
+			expectedErrors: []Phase{PhaseParse},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUtils := mocks.NewMockUtilProvider(ctrl)
+			utilsInstance = mockUtils
+			test.setup(mockUtils)
+
+			res := pageAnalyzer.Analyze(pageURL)
+
+			expectedPhases := []Phase{PhaseFetch, PhaseParse, PhaseLinkDiscovery, PhaseChecks, PhaseLinkCheck}
+			if len(res.Stages) != len(expectedPhases) {
+				t.Fatalf("Expected %d stages, got %v", len(expectedPhases), res.Stages)
+			}
+			for i, stage := range res.Stages {
+				if stage.Stage != expectedPhases[i] || stage.Status != test.expectedStages[i] {
+					t.Errorf("Expected stage '%s' to be '%s', got '%s' '%s'", expectedPhases[i], test.expectedStages[i], stage.Stage, stage.Status)
+				}
+			}
+
+			if res.ErrorCode != test.expectedCode {
+				t.Errorf("Expected error code '%s', got '%s'", test.expectedCode, res.ErrorCode)
+			}
+			if len(res.Errors) != len(test.expectedErrors) {
+				t.Fatalf("Expected errors of stages %v, got %v", test.expectedErrors, res.Errors)
+			}
+			for i, stageErr := range res.Errors {
+				if stageErr.Stage != test.expectedErrors[i] || stageErr.Code != test.expectedCode {
+					t.Errorf("Expected error of stage '%s' with code '%s', got %v", test.expectedErrors[i], test.expectedCode, stageErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"time"
+)
+
+// Statuses of a stage of the analysis pipeline
+const (
+	StageOK = "ok"
+	// StageFailed stages stop the pipeline, the following stages are skipped
+	StageFailed = "failed"
+	// StagePartial stages were interrupted, their results are kept but incomplete
+	StagePartial = "partial"
+	StageSkipped = "skipped"
+)
+
+// StageReport is the outcome of a stage of the analysis pipeline
+type StageReport struct {
+	Stage      Phase  `json:"stage"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// StageError is the error of a stage that failed or was interrupted
+type StageError struct {
+	Stage   Phase  `json:"stage"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Detail is the underlying error, meant for debugging
+	Detail string `json:"detail"`
+}
+
+// stage is a step of the analysis pipeline. The stages are run in order; when a stage fails
+// the following ones are skipped, unless the stage is partial.
+type stage struct {
+	phase Phase
+	// partial stages keep their results when they fail and do not stop the pipeline
+	partial bool
+	run     func() error
+}
+
+// runStages runs the stages, records their outcome in the result and reports each started
+// stage to the progress function. The first error sets the error code and message of the result.
+func runStages(stages []stage, res *Result, progress ProgressFunc) {
+	stopped := false
+	for _, s := range stages {
+		if stopped {
+			res.Stages = append(res.Stages, StageReport{Stage: s.phase, Status: StageSkipped})
+			continue
+		}
+
+		progress.emit(s.phase, res)
+		start := time.Now()
+		err := s.run()
+		report := StageReport{Stage: s.phase, Status: StageOK, DurationMS: time.Since(start).Milliseconds()}
+
+		if err != nil {
+			report.Status = StageFailed
+			if s.partial {
+				report.Status = StagePartial
+			} else {
+				stopped = true
+			}
+			res.addError(s.phase, err)
+		}
+		res.Stages = append(res.Stages, report)
+	}
+}
+
+// StageFailed reports whether a stage of the analysis failed, in which case the following
+// stages were skipped and the result is incomplete
+func (r *Result) StageFailed() bool {
+	for _, stage := range r.Stages {
+		if stage.Status == StageFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// StageStatus returns the status of the stage of the analysis, empty if it was not run
+func (r *Result) StageStatus(phase Phase) string {
+	for _, stage := range r.Stages {
+		if stage.Stage == phase {
+			return stage.Status
+		}
+	}
+	return ""
+}
+
+// addError records the error of a stage. The first error is the error of the analysis.
+func (r *Result) addError(phase Phase, err error) {
+	stageErr := StageError{
+		Stage:   phase,
+		Code:    handleErrorCode(err),
+		Message: handleErrorMsg(err),
+		Detail:  err.Error(),
+	}
+	r.Errors = append(r.Errors, stageErr)
+
+	if r.ErrorCode == "" {
+		r.ErrorCode = stageErr.Code
+		r.ErrorMessage = stageErr.Message
+	}
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+import "testing"
+
+func TestStageFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		stages   []string
+		expected bool
+	}{
+		{
+			name:   "Stages succeeded",
+			stages: []string{StageOK, StageOK},
+		},
+		{
+			name:   "Stage interrupted",
+			stages: []string{StageOK, StagePartial},
+		},
+		{
+			name:     "Stage failed",
+			stages:   []string{StageOK, StageFailed, StageSkipped},
+			expected: true,
+		},
+		{
+			name: "No stage run",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := &Result{}
+			for _, status := range test.stages {
+				res.Stages = append(res.Stages, StageReport{Status: status})
+			}
+
+			if failed := res.StageFailed(); failed != test.expected {
+				t.Errorf("Expected failed %v, got %v", test.expected, failed)
+			}
+		})
+	}
+}
+
+func TestStageStatus(t *testing.T) {
+	res := &Result{Stages: []StageReport{
+		{Stage: PhaseFetch, Status: StageOK},
+		{Stage: PhaseLinkCheck, Status: StagePartial},
+	}}
+
+	if status := res.StageStatus(PhaseLinkCheck); status != StagePartial {
+		t.Errorf("Expected status '%s', got '%s'", StagePartial, status)
+	}
+	if status := res.StageStatus(PhaseParse); status != "" {
+		t.Errorf("Expected no status for a stage not run, got '%s'", status)
+	}
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import "github.com/isurukdniss/webpage-analyzer/utils"
+
+// Phase identifies a stage of the analysis reported through progress events
+type Phase string
+
+// Phases of the analysis in the order they are reported
+const (
+	PhaseFetch         Phase = "fetch"
+	PhaseParse         Phase = "parse"
+	PhaseLinkDiscovery Phase = "link_discovery"
+	PhaseChecks        Phase = "checks"
+	PhaseLinkCheck     Phase = "link_check"
+	PhaseDone          Phase = "done"
+)
+
+// Event describes the progress of an analysis. A phase event is emitted when a new phase
+// starts and carries a snapshot of the partial result. During PhaseLinkCheck a link event
+// is emitted for each checked link.
+type Event struct {
+	Phase Phase `json:"phase"`
+	// Result is a snapshot of the partial result at the time of a phase event
+	Result *Result `json:"result,omitempty"`
+	// Link is the report of a single link check
+	Link *LinkReport `json:"link,omitempty"`
+	// Checked and Total count the links checked so far and the links to check
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+// ProgressFunc receives the progress events emitted during an analysis. It is never
+// called concurrently.
+type ProgressFunc func(Event)
+
+func (p ProgressFunc) emit(phase Phase, res *Result) {
+	if p == nil {
+		return
+	}
+	ev := Event{Phase: phase, Result: res.clone(), Total: len(res.InternalLinks) + len(res.ExternalLinks)}
+	if phase == PhaseDone {
+		ev.Checked = ev.Total
+	}
+	p(ev)
+}
+
+func (p ProgressFunc) emitLink(link LinkReport, checked, total int) {
+	if p == nil {
+		return
+	}
+	p(Event{Phase: PhaseLinkCheck, Link: &link, Checked: checked, Total: total})
+}
+
+// clone returns a deep copy of the result so that it can be shared while the analysis continues
+func (r *Result) clone() *Result {
+	c := *r
+
+	c.HeadingsCount = make(map[string]int, len(r.HeadingsCount))
+	for k, v := range r.HeadingsCount {
+		c.HeadingsCount[k] = v
+	}
+
+	c.LinkCategories = make(map[string]int, len(r.LinkCategories))
+	for k, v := range r.LinkCategories {
+		c.LinkCategories[k] = v
+	}
+
+	if r.InternalLinks != nil {
+		c.InternalLinks = append([]string(nil), r.InternalLinks...)
+	}
+	if r.ExternalLinks != nil {
+		c.ExternalLinks = append([]string(nil), r.ExternalLinks...)
+	}
+
+	if r.Redirects != nil {
+		c.Redirects = append([]utils.Redirect(nil), r.Redirects...)
+	}
+	if r.Charset.Conflicts != nil {
+		c.Charset.Conflicts = append([]utils.CharsetDeclaration(nil), r.Charset.Conflicts...)
+	}
+	if r.LinkWarnings != nil {
+		c.LinkWarnings = append([]LinkWarning(nil), r.LinkWarnings...)
+	}
+	if r.Checks != nil {
+		c.Checks = append([]string(nil), r.Checks...)
+	}
+	if r.Findings != nil {
+		c.Findings = append([]Finding(nil), r.Findings...)
+	}
+	if r.Links != nil {
+		c.Links = append([]LinkReport(nil), r.Links...)
+	}
+	if r.Stages != nil {
+		c.Stages = append([]StageReport(nil), r.Stages...)
+	}
+	if r.Errors != nil {
+		c.Errors = append([]StageError(nil), r.Errors...)
+	}
+
+	return &c
+}
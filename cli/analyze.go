@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/config"
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+// analyze analyzes the URLs given as arguments or listed in the input file one after the other.
+// The text output is written as each analysis completes, the other formats once all are done.
+// With a -policy file each result is checked against it.
+func analyze(ctx context.Context, env *Env, args []string) int {
+	var format outputFormat = report.FormatText
+	var input string
+	cfg, urls, err := config.LoadCommand(args, func(fs *flag.FlagSet) {
+		fs.SetOutput(env.Stderr)
+		fs.Usage = commandUsage(fs, "analyze [flags] <url>...",
+			"Analyzes the web pages and prints the results. The flags must precede the URLs.")
+		fs.Var(&format, "format", "output format: "+strings.Join(report.Formats(), ", "))
+		fs.StringVar(&input, "input", "", "file listing the URLs to analyze one per line, - for the standard input")
+	})
+	if code, done := loadFailed(env, err); done {
+		return code
+	}
+
+	if input != "" {
+		listed, err := readURLs(env, input)
+		if err != nil {
+			env.logger().Println(err)
+			return ExitUsage
+		}
+		urls = append(urls, listed...)
+	}
+	if len(urls) == 0 {
+		env.logger().Println("no URL to analyze")
+		return ExitUsage
+	}
+
+	pageAnalyzer, pagePolicy, err := setup(cfg, env.logger())
+	if err != nil {
+		env.logger().Println(err)
+		return ExitUsage
+	}
+
+	code := ExitOK
+	writer := format.writer()
+	analyses := make([]report.Analysis, 0, len(urls))
+	for _, pageURL := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		a := report.Analysis{URL: pageURL, Result: pageAnalyzer.AnalyzeContext(ctx, pageURL, nil)}
+		if pagePolicy != nil {
+			a.Policy = pagePolicy.Evaluate(a.Result)
+		}
+		analyses = append(analyses, a)
+
+		switch {
+		case a.Result.ErrorCode != "":
+			code = ExitFailure
+		case a.Policy != nil && !a.Policy.Passed && code == ExitOK:
+			code = ExitPolicyFailed
+		}
+		if format == report.FormatText {
+			if err := writer.WriteAnalyses(env.Stdout, []report.Analysis{a}); err != nil {
+				env.logger().Println(err)
+				return ExitFailure
+			}
+		}
+	}
+
+	if format != report.FormatText {
+		if err := writer.WriteAnalyses(env.Stdout, analyses); err != nil {
+			env.logger().Println(err)
+			return ExitFailure
+		}
+	}
+	if ctx.Err() != nil {
+		return ExitInterrupted
+	}
+	return code
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+// siteServer serves a small site: a home page linking to an about page and to a missing page
+func siteServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `<!DOCTYPE html><html><head><title>Home</title></head><body>
+			<h1>Home</h1><a href="/about">About</a><a href="/missing">Missing</a></body></html>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<!DOCTYPE html><html><head><title>About</title></head><body><h2>About</h2></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// policyFile writes a policy requiring a single h1 and warning about inaccessible links
+func policyFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	data := `{"rules": [
+		{"name": "Single h1", "metric": "h1_count", "fail_below": 1, "fail_above": 1},
+		{"metric": "inaccessible_links", "warn_above": 0}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnalyze(t *testing.T) {
+	server := siteServer(t)
+	// The test server listens on a loopback address
+	allow := []string{"-http-allow-hosts", "127.0.0.1", "-retry-max-attempts", "1"}
+	policyPath := policyFile(t)
+
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedOutput []string
+	}{
+		{
+			name:         "Text output",
+			args:         []string{server.URL + "/"},
+			expectedCode: ExitOK,
+			expectedOutput: []string{
+				server.URL + "/\n",
+				"Status        200",
+				"Title         Home",
+				"Headings      h1: 1",
+				"Links         2 internal, 0 external, 1 inaccessible",
+				"Inaccessible links:",
+				"broken  404  " + server.URL + "/missing",
+			},
+		},
+		{
+			name:           "Failed analysis",
+			args:           []string{server.URL + "/about", server.URL + "/missing"},
+			expectedCode:   ExitFailure,
+			expectedOutput: []string{"Title         About", "upstream_status: The server returned a status code of 404."},
+		},
+		{
+			name:           "URLs from the standard input",
+			args:           []string{"-input", "-", server.URL + "/"},
+			stdin:          server.URL + "/about\n",
+			expectedCode:   ExitOK,
+			expectedOutput: []string{"Title         Home", "Title         About"},
+		},
+		{
+			name:         "Policy warning",
+			args:         []string{"-policy", policyPath, server.URL + "/"},
+			expectedCode: ExitOK,
+			expectedOutput: []string{
+				"Policy        warn (0 failed, 1 warned, 1 passed)",
+				"warn  inaccessible_links  inaccessible_links is 1, above the warning threshold of 0",
+			},
+		},
+		{
+			name:         "Policy failure",
+			args:         []string{"-policy", policyPath, server.URL + "/", server.URL + "/about"},
+			expectedCode: ExitPolicyFailed,
+			expectedOutput: []string{
+				"Policy        fail (1 failed, 0 warned, 1 passed)",
+				"fail  Single h1  h1_count is 0, below the failure threshold of 1",
+			},
+		},
+		{
+			name:           "Failed analysis takes precedence over the policy",
+			args:           []string{"-policy", policyPath, server.URL + "/about", server.URL + "/missing"},
+			expectedCode:   ExitFailure,
+			expectedOutput: []string{"fail  analysis"},
+		},
+		{
+			name:         "CSV output",
+			args:         []string{"-format", "csv", server.URL + "/"},
+			expectedCode: ExitOK,
+			expectedOutput: []string{
+				"page_url,link_url,internal,state,",
+				server.URL + "/," + server.URL + "/missing,true,broken,false,404,",
+			},
+		},
+		{
+			name:           "Markdown output",
+			args:           []string{"-format", "markdown", server.URL + "/", server.URL + "/about"},
+			expectedCode:   ExitOK,
+			expectedOutput: []string{"# Webpage Analysis Report", "| Title | Home |", "| Title | About |"},
+		},
+		{
+			name:         "Invalid policy file",
+			args:         []string{"-policy", filepath.Join(t.TempDir(), "missing.json"), server.URL + "/"},
+			expectedCode: ExitUsage,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append(append([]string{"analyze"}, allow...), test.args...)
+			code, stdout, stderr := run(t, test.stdin, args...)
+
+			if code != test.expectedCode {
+				t.Errorf("Expected exit code %d, got %d (%s)", test.expectedCode, code, stderr)
+			}
+			for _, expected := range test.expectedOutput {
+				if !strings.Contains(stdout, expected) {
+					t.Errorf("Expected '%s' in the output, got\n%s", expected, stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeJSON(t *testing.T) {
+	server := siteServer(t)
+
+	code, stdout, stderr := run(t, "", "analyze", "-format", "json", "-http-allow-hosts", "127.0.0.1",
+		server.URL+"/", server.URL+"/missing")
+
+	if code != ExitFailure {
+		t.Errorf("Expected exit code %d, got %d (%s)", ExitFailure, code, stderr)
+	}
+	var analyses []report.Analysis
+	if err := json.Unmarshal([]byte(stdout), &analyses); err != nil {
+		t.Fatalf("Expected a JSON array, got error %v", err)
+	}
+	if len(analyses) != 2 {
+		t.Fatalf("Expected 2 analyses, got %d", len(analyses))
+	}
+	if analyses[0].URL != server.URL+"/" || analyses[0].Result.Title != "Home" {
+		t.Errorf("Expected the analysis of the home page first, got %s with title '%s'", analyses[0].URL, analyses[0].Result.Title)
+	}
+	if analyses[1].Result.ErrorCode != "upstream_status" {
+		t.Errorf("Expected the missing page to fail with 'upstream_status', got '%s'", analyses[1].Result.ErrorCode)
+	}
+}
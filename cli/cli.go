@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Exit codes of the commands
+const (
+	// ExitOK is returned when every analysis succeeded
+	ExitOK = 0
+	// ExitFailure is returned when an analysis failed, eg. the page could not be fetched
+	ExitFailure = 1
+	// ExitUsage is returned for invalid arguments or configuration
+	ExitUsage = 2
+	// ExitPolicyFailed is returned when the analyses succeeded but a page fails the policy
+	ExitPolicyFailed = 3
+	// ExitInterrupted is returned when the command is interrupted, as shells do for SIGINT
+	ExitInterrupted = 130
+)
+
+// Env holds the standard streams of the commands
+type Env struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func (e *Env) logger() *log.Logger {
+	return log.New(e.Stderr, "", log.LstdFlags)
+}
+
+// command is a subcommand of the CLI, run with the arguments following its name
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env *Env, args []string) int
+}
+
+var commands = []command{
+	{name: "serve", summary: "Run the web UI and the JSON API (default)", run: serve},
+	{name: "analyze", summary: "Analyze web pages and print the results", run: analyze},
+	{name: "crawl", summary: "Crawl a site from a seed URL and print the pages and the summary", run: crawl},
+}
+
+// Run runs the command named by the first argument and returns its exit code. Without a
+// command, or when the first argument is a flag, the server is run. The commands stop when the
+// context is canceled.
+func Run(ctx context.Context, args []string, env *Env) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(ctx, env, args)
+	}
+
+	switch args[0] {
+	case "help":
+		usage(env.Stdout)
+		return ExitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, env, args[1:])
+		}
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown command %q\n\n", args[0])
+	usage(env.Stderr)
+	return ExitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: webpage-analyzer <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'webpage-analyzer <command> -h' for the flags of a command.")
+}
+
+// commandUsage returns the usage function of the flag set of a command
+func commandUsage(fs *flag.FlagSet, synopsis, description string) func() {
+	return func() {
+		fmt.Fprintf(fs.Output(), "Usage: webpage-analyzer %s\n\n%s\n\nFlags:\n", synopsis, description)
+		fs.PrintDefaults()
+	}
+}
+
+// loadFailed reports the error of the configuration of a command. done is false if there
+// is no error.
+func loadFailed(env *Env, err error) (code int, done bool) {
+	switch {
+	case err == nil:
+		return ExitOK, false
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK, true
+	}
+	env.logger().Println(err)
+	return ExitUsage, true
+}
+
+// readURLs reads the URLs listed one per line in the file, or in the standard input if the
+// path is "-". The blank lines and the lines starting with # are ignored.
+func readURLs(env *Env, path string) ([]string, error) {
+	r := env.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
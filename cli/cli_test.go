@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// run runs the CLI with the given arguments and standard input and returns the exit code and
+// the standard output and error
+func run(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	env := &Env{Stdin: strings.NewReader(stdin), Stdout: &stdout, Stderr: &stderr}
+	code := Run(context.Background(), args, env)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Help",
+			args:           []string{"help"},
+			expectedCode:   ExitOK,
+			expectedStdout: "Usage: webpage-analyzer <command>",
+		},
+		{
+			name:           "Unknown command",
+			args:           []string{"inspect", "https://example.com/"},
+			expectedCode:   ExitUsage,
+			expectedStderr: `unknown command "inspect"`,
+		},
+		{
+			name:           "Help of a command",
+			args:           []string{"analyze", "-h"},
+			expectedCode:   ExitOK,
+			expectedStderr: "Usage: webpage-analyzer analyze [flags] <url>...",
+		},
+		{
+			name:           "Invalid flag of a command",
+			args:           []string{"analyze", "-format", "yaml", "https://example.com/"},
+			expectedCode:   ExitUsage,
+			expectedStderr: `invalid output format "yaml"`,
+		},
+		{
+			name:           "Invalid configuration flag",
+			args:           []string{"crawl", "-robots", "ignore", "https://example.com/"},
+			expectedCode:   ExitUsage,
+			expectedStderr: `invalid robots mode "ignore"`,
+		},
+		{
+			name:           "No URL to analyze",
+			args:           []string{"analyze"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "no URL to analyze",
+		},
+		{
+			name:           "Several URLs to crawl",
+			args:           []string{"crawl", "https://example.com/", "https://example.org/"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "expected a single URL to crawl",
+		},
+		{
+			name:           "Unexpected arguments of the server",
+			args:           []string{"serve", "https://example.com/"},
+			expectedCode:   ExitUsage,
+			expectedStderr: "unexpected arguments",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, stdout, stderr := run(t, "", test.args...)
+
+			if code != test.expectedCode {
+				t.Errorf("Expected exit code %d, got %d", test.expectedCode, code)
+			}
+			if !strings.Contains(stdout, test.expectedStdout) {
+				t.Errorf("Expected '%s' in the output, got '%s'", test.expectedStdout, stdout)
+			}
+			if !strings.Contains(stderr, test.expectedStderr) {
+				t.Errorf("Expected '%s' in the errors, got '%s'", test.expectedStderr, stderr)
+			}
+		})
+	}
+}
+
+func TestReadURLs(t *testing.T) {
+	list := "# Pages of the nightly report\nhttps://example.com/\n\n  https://example.com/about  \n#https://example.com/old\n"
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(path, []byte(list), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"https://example.com/", "https://example.com/about"}
+
+	tests := []struct {
+		name     string
+		path     string
+		hasError bool
+	}{
+		{name: "File", path: path},
+		{name: "Standard input", path: "-"},
+		{name: "Missing file", path: filepath.Join(t.TempDir(), "missing.txt"), hasError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := &Env{Stdin: strings.NewReader(list)}
+			urls, err := readURLs(env, test.path)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(urls, expected) {
+				t.Errorf("Expected URLs %v, got %v", expected, urls)
+			}
+		})
+	}
+}
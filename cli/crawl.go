@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/config"
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+// crawl crawls the site of the seed URL and writes the crawled pages and the site summary
+func crawl(ctx context.Context, env *Env, args []string) int {
+	var format outputFormat = report.FormatText
+	cfg, rest, err := config.LoadCommand(args, func(fs *flag.FlagSet) {
+		fs.SetOutput(env.Stderr)
+		fs.Usage = commandUsage(fs, "crawl [flags] <url>",
+			"Crawls the site of the URL up to -crawl-max-depth and -crawl-max-pages and prints the pages and the summary of the site. The flags must precede the URL.")
+		fs.Var(&format, "format", "output format: "+strings.Join(report.Formats(), ", "))
+	})
+	if code, done := loadFailed(env, err); done {
+		return code
+	}
+	if len(rest) != 1 {
+		env.logger().Println("expected a single URL to crawl")
+		return ExitUsage
+	}
+
+	pageAnalyzer, pagePolicy, err := setup(cfg, env.logger())
+	if err != nil {
+		env.logger().Println(err)
+		return ExitUsage
+	}
+
+	res := newCrawler(cfg, pageAnalyzer, pagePolicy).Crawl(ctx, rest[0])
+
+	if err := format.writer().WriteCrawl(env.Stdout, res); err != nil {
+		env.logger().Println(err)
+		return ExitFailure
+	}
+
+	// The crawl fails when its seed cannot be analyzed
+	switch {
+	case ctx.Err() != nil:
+		return ExitInterrupted
+	case len(res.Pages) == 1 && res.Pages[0].Result.ErrorCode != "":
+		return ExitFailure
+	case len(res.Summary.PolicyFailedPages) > 0:
+		return ExitPolicyFailed
+	}
+	return ExitOK
+}
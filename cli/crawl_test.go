@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+func TestCrawl(t *testing.T) {
+	server := siteServer(t)
+	allow := []string{"-http-allow-hosts", "127.0.0.1", "-retry-max-attempts", "1"}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedOutput []string
+	}{
+		{
+			name:         "Site crawled",
+			args:         []string{server.URL + "/"},
+			expectedCode: ExitOK,
+			expectedOutput: []string{
+				"Crawl of " + server.URL + "/",
+				"0      200     " + server.URL + "/",
+				"1      200     " + server.URL + "/about",
+				"Failed pages             1",
+				"Pages without an h1      1",
+				"broken  404  " + server.URL + "/missing  linked from 1 page(s)",
+			},
+		},
+		{
+			name:           "Seed not found",
+			args:           []string{server.URL + "/missing"},
+			expectedCode:   ExitFailure,
+			expectedOutput: []string{"0      404     " + server.URL + "/missing"},
+		},
+		{
+			name:           "HTML output",
+			args:           []string{"-format", "html", server.URL + "/"},
+			expectedCode:   ExitOK,
+			expectedOutput: []string{"<!DOCTYPE html>", "<h1>Crawl of " + server.URL + "/</h1>", "<td>" + server.URL + "/about</td>"},
+		},
+		{
+			name:         "JUnit output",
+			args:         []string{"-format", "junit", server.URL + "/"},
+			expectedCode: ExitOK,
+			expectedOutput: []string{
+				`<testsuite name="` + server.URL + `/about" tests="6" failures="0" skipped="0">`,
+				`<failure message="upstream_status: The server returned a status code of 404.`,
+			},
+		},
+		{
+			name:         "Pages failing the policy",
+			args:         []string{"-policy", policyFile(t), server.URL + "/"},
+			expectedCode: ExitPolicyFailed,
+			expectedOutput: []string{
+				"Pages failing the policy  2",
+				server.URL + "/about    Single h1  h1_count is 0, below the failure threshold of 1",
+				server.URL + "/missing  analysis",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := append(append([]string{"crawl"}, allow...), test.args...)
+			code, stdout, stderr := run(t, "", args...)
+
+			if code != test.expectedCode {
+				t.Errorf("Expected exit code %d, got %d (%s)", test.expectedCode, code, stderr)
+			}
+			for _, expected := range test.expectedOutput {
+				if !strings.Contains(stdout, expected) {
+					t.Errorf("Expected '%s' in the output, got\n%s", expected, stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlJSON(t *testing.T) {
+	server := siteServer(t)
+
+	code, stdout, stderr := run(t, "", "crawl", "-format", "json", "-crawl-max-depth", "0", "-http-allow-hosts", "127.0.0.1", server.URL)
+
+	if code != ExitOK {
+		t.Errorf("Expected exit code %d, got %d (%s)", ExitOK, code, stderr)
+	}
+	var res crawler.Result
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("Expected a JSON crawl result, got error %v", err)
+	}
+	if res.Summary.TotalPages != 1 || res.Pages[0].Result.Title != "Home" {
+		t.Errorf("Expected the seed page only, got %d pages", res.Summary.TotalPages)
+	}
+}
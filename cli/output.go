@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+// outputFormat is the -format flag of the commands, the format of a report writer
+type outputFormat string
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Set(val string) error {
+	if _, ok := report.Lookup(val); !ok {
+		return fmt.Errorf("invalid output format %q, expected one of %s", val, strings.Join(report.Formats(), ", "))
+	}
+	*f = outputFormat(val)
+	return nil
+}
+
+// writer returns the report writer of the format
+func (f outputFormat) writer() report.Writer {
+	w, _ := report.Lookup(string(f))
+	return w
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		val      string
+		hasError bool
+	}{
+		{val: "text"},
+		{val: "json"},
+		{val: "csv"},
+		{val: "markdown"},
+		{val: "html"},
+		{val: "junit"},
+		{val: "sarif"},
+		{val: "JSON", hasError: true},
+		{val: "", hasError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.val, func(t *testing.T) {
+			var format outputFormat = report.FormatText
+			err := format.Set(test.val)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil || string(format) != test.val {
+				t.Errorf("Expected format '%s', got '%s' and error %v", test.val, format, err)
+			}
+		})
+	}
+}
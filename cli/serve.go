@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+
+	"github.com/isurukdniss/webpage-analyzer/config"
+	"github.com/isurukdniss/webpage-analyzer/handler"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+)
+
+var stylesPathPattern = "/styles/"
+var stylesDir = "web/styles"
+
+// serve runs the HTTP server until the context is canceled, then waits for the in-flight
+// requests up to the shutdown timeout
+func serve(ctx context.Context, env *Env, args []string) int {
+	cfg, rest, err := config.LoadCommand(args, func(fs *flag.FlagSet) {
+		fs.SetOutput(env.Stderr)
+		fs.Usage = commandUsage(fs, "serve [flags]", "Runs the web UI and the JSON API.")
+	})
+	if code, done := loadFailed(env, err); done {
+		return code
+	}
+	if len(rest) > 0 {
+		env.logger().Printf("unexpected arguments: %v", rest)
+		return ExitUsage
+	}
+
+	pageAnalyzer, pagePolicy, err := setup(cfg, env.logger())
+	if err != nil {
+		env.logger().Println(err)
+		return ExitUsage
+	}
+	handler.SetAnalyzer(pageAnalyzer)
+	handler.SetPolicy(pagePolicy)
+
+	jobManager := jobs.NewManager(pageAnalyzer, jobs.Config{
+		Workers:   cfg.Jobs.Workers,
+		QueueSize: cfg.Jobs.QueueSize,
+		ResultTTL: cfg.Jobs.ResultTTL,
+	})
+	defer jobManager.Close()
+	handler.SetJobManager(jobManager)
+
+	handler.SetCrawler(newCrawler(cfg, pageAnalyzer, pagePolicy))
+
+	mux := http.NewServeMux()
+	fs := http.FileServer(http.Dir(stylesDir))
+	mux.Handle(stylesPathPattern, http.StripPrefix(stylesPathPattern, fs))
+
+	mux.HandleFunc("/", handler.IndexHandler)
+	mux.HandleFunc("/analyze", handler.AnalyzeHandler)
+	mux.HandleFunc(handler.ReportPath, handler.ReportHandler)
+	mux.HandleFunc(handler.APIVersionPrefix+"/analyze", handler.APIAnalyzeHandler)
+	mux.HandleFunc(handler.CrawlPath, handler.APICrawlHandler)
+	mux.HandleFunc(handler.SitemapPath, handler.APISitemapHandler)
+	mux.HandleFunc(handler.JobsPath, handler.JobsHandler)
+	mux.HandleFunc(handler.JobsPath+"/", handler.JobsHandler)
+
+	server := &http.Server{
+		Addr:    cfg.Server.Addr,
+		Handler: mux,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		env.logger().Printf("Server running at %s", cfg.Server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		env.logger().Println(err)
+		return ExitFailure
+	case <-ctx.Done():
+	}
+	env.logger().Println("Shutting down the server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		env.logger().Println(err)
+	}
+	return ExitOK
+}
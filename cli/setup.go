@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"log"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/config"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// setup builds the helpers of the configuration, sets them on the analyzer and the crawler
+// packages and returns the page analyzer and the policy of the configuration. The policy is nil
+// when no policy file is configured.
+func setup(cfg *config.Config, logger *log.Logger) (*analyzer.Analyzer, *policy.Policy, error) {
+	helpers, err := utils.New(utils.ClientConfig{
+		ConnectTimeout:      cfg.HTTP.ConnectTimeout,
+		ReadTimeout:         cfg.HTTP.ReadTimeout,
+		FetchTimeout:        cfg.HTTP.FetchTimeout,
+		LinkCheckTimeout:    cfg.HTTP.LinkCheckTimeout,
+		UserAgent:           cfg.HTTP.UserAgent,
+		Headers:             cfg.HTTP.Headers,
+		Proxy:               cfg.HTTP.Proxy,
+		CABundle:            cfg.HTTP.CABundle,
+		Insecure:            cfg.HTTP.Insecure,
+		MaxIdleConns:        cfg.HTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+		MaxRedirects:        cfg.HTTP.MaxRedirects,
+		MaxBodySize:         cfg.HTTP.MaxBodySize,
+		AllowHosts:          cfg.HTTP.AllowHosts,
+		DenyHosts:           cfg.HTTP.DenyHosts,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var pagePolicy *policy.Policy
+	if cfg.Policy.File != "" {
+		if pagePolicy, err = policy.Load(cfg.Policy.File); err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg.HTTP.Insecure {
+		logger.Println("Warning: the verification of the server certificates is disabled")
+	}
+	helpers.LinkStatusStates = cfg.Analysis.LinkStatusStates
+	helpers.MaxRetryAfter = cfg.Analysis.LinkMaxRetryAfter
+	helpers.Retry = utils.RetryPolicy{
+		MaxAttempts:           cfg.Retry.MaxAttempts,
+		BaseDelay:             cfg.Retry.BaseDelay,
+		MaxDelay:              cfg.Retry.MaxDelay,
+		RetryableErrorClasses: cfg.Retry.ErrorClasses,
+		RetryableStatusCodes:  cfg.Retry.StatusCodes,
+	}
+	helpers.Robots = utils.RobotsPolicy{
+		Mode:          string(cfg.Robots.Mode),
+		MaxCrawlDelay: cfg.Robots.MaxCrawlDelay,
+	}
+	analyzer.SetUtils(helpers)
+	crawler.SetUtils(helpers)
+
+	return &analyzer.Analyzer{
+		Timeout:            cfg.Analysis.Timeout,
+		MaxConcurrency:     cfg.Analysis.LinkConcurrency,
+		PerHostConcurrency: cfg.Analysis.LinkHostConcurrency,
+		PerHostDelay:       cfg.Analysis.LinkHostDelay,
+		DisabledChecks:     cfg.Analysis.DisabledChecks,
+	}, pagePolicy, nil
+}
+
+// newCrawler returns the crawler of the configuration
+func newCrawler(cfg *config.Config, pageAnalyzer analyzer.PageAnalyzer, pagePolicy *policy.Policy) *crawler.Crawler {
+	return &crawler.Crawler{
+		Analyzer:    pageAnalyzer,
+		MaxDepth:    cfg.Crawl.MaxDepth,
+		MaxPages:    cfg.Crawl.MaxPages,
+		Concurrency: cfg.Crawl.Concurrency,
+		Policy:      pagePolicy,
+	}
+}
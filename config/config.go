@@ -0,0 +1,590 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// EnvPrefix is prepended to the upper-cased flag names to form the environment variable names,
+// eg. the -job-workers flag can be set with ANALYZER_JOB_WORKERS
+const EnvPrefix = "ANALYZER_"
+
+// Config holds the runtime configuration of the application
+type Config struct {
+	Server   ServerConfig
+	Analysis AnalysisConfig
+	Retry    RetryConfig
+	HTTP     HTTPConfig
+	Jobs     JobsConfig
+	Crawl    CrawlConfig
+	Robots   RobotsConfig
+	Policy   PolicyConfig
+}
+
+// ServerConfig holds the settings of the HTTP server
+type ServerConfig struct {
+	Addr            string
+	ShutdownTimeout time.Duration
+}
+
+// AnalysisConfig holds the settings of a single page analysis
+type AnalysisConfig struct {
+	Timeout             time.Duration
+	LinkConcurrency     int
+	LinkHostConcurrency int
+	LinkHostDelay       time.Duration
+	LinkStatusStates    LinkStatusStates
+	LinkMaxRetryAfter   time.Duration
+	DisabledChecks      CheckIDs
+}
+
+// LinkStatusStates maps status codes to link states. As a flag it is given as a comma
+// separated list of code=state pairs, eg. 401=restricted,429=rate_limited.
+type LinkStatusStates map[int]string
+
+func (s LinkStatusStates) String() string {
+	codes := make([]int, 0, len(s))
+	for code := range s {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	pairs := make([]string, len(codes))
+	for i, code := range codes {
+		pairs[i] = fmt.Sprintf("%d=%s", code, s[code])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set replaces the mapping with the given list of code=state pairs
+func (s LinkStatusStates) Set(val string) error {
+	states := make(map[int]string)
+	for _, pair := range splitList(val) {
+
+		codeVal, state, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid pair %q, expected code=state", pair)
+		}
+		code, err := parseStatusCode(strings.TrimSpace(codeVal))
+		if err != nil {
+			return err
+		}
+		state = strings.TrimSpace(state)
+		if !slices.Contains(utils.LinkStates, state) {
+			return fmt.Errorf("invalid link state %q, expected one of %s", state, strings.Join(utils.LinkStates, ", "))
+		}
+		states[code] = state
+	}
+
+	for code := range s {
+		delete(s, code)
+	}
+	for code, state := range states {
+		s[code] = state
+	}
+	return nil
+}
+
+// RetryConfig holds the retry policy of the page fetch and the link checks
+type RetryConfig struct {
+	MaxAttempts  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	ErrorClasses ErrorClasses
+	StatusCodes  StatusCodes
+}
+
+// ErrorClasses is a list of error classes. As a flag it is given as a comma separated list.
+type ErrorClasses []string
+
+func (c *ErrorClasses) String() string {
+	return strings.Join(*c, ",")
+}
+
+// Set replaces the list with the given comma separated error classes
+func (c *ErrorClasses) Set(val string) error {
+	classes := []string{}
+	for _, class := range splitList(val) {
+		if !slices.Contains(utils.ErrorClasses, class) {
+			return fmt.Errorf("invalid error class %q, expected one of %s", class, strings.Join(utils.ErrorClasses, ", "))
+		}
+		classes = append(classes, class)
+	}
+	*c = classes
+	return nil
+}
+
+// CheckIDs is a list of IDs of registered checks. As a flag it is given as a comma separated list.
+type CheckIDs []string
+
+func (c *CheckIDs) String() string {
+	return strings.Join(*c, ",")
+}
+
+// Set replaces the list with the given comma separated check IDs
+func (c *CheckIDs) Set(val string) error {
+	ids := []string{}
+	for _, id := range splitList(val) {
+		if _, ok := analyzer.LookupCheck(id); !ok {
+			return fmt.Errorf("invalid check %q, expected one of %s", id, strings.Join(analyzer.CheckIDs(), ", "))
+		}
+		ids = append(ids, id)
+	}
+	*c = ids
+	return nil
+}
+
+// StatusCodes is a list of HTTP status codes. As a flag it is given as a comma separated list.
+type StatusCodes []int
+
+func (c *StatusCodes) String() string {
+	codes := make([]string, len(*c))
+	for i, code := range *c {
+		codes[i] = strconv.Itoa(code)
+	}
+	return strings.Join(codes, ",")
+}
+
+// Set replaces the list with the given comma separated status codes
+func (c *StatusCodes) Set(val string) error {
+	codes := []int{}
+	for _, item := range splitList(val) {
+		code, err := parseStatusCode(item)
+		if err != nil {
+			return err
+		}
+		codes = append(codes, code)
+	}
+	*c = codes
+	return nil
+}
+
+// HTTPConfig holds the settings of the HTTP client used to fetch the pages and check the links
+type HTTPConfig struct {
+	ConnectTimeout      time.Duration
+	ReadTimeout         time.Duration
+	FetchTimeout        time.Duration
+	LinkCheckTimeout    time.Duration
+	UserAgent           string
+	Headers             Headers
+	Proxy               string
+	CABundle            string
+	Insecure            bool
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxRedirects        int
+	MaxBodySize         int64
+	AllowHosts          Hosts
+	DenyHosts           Hosts
+}
+
+// Hosts is a list of host names, "*.domain" wildcards, IP addresses and CIDR ranges. As a flag
+// it is given as a comma separated list.
+type Hosts []string
+
+func (h *Hosts) String() string {
+	return strings.Join(*h, ",")
+}
+
+// Set replaces the list with the given comma separated hosts
+func (h *Hosts) Set(val string) error {
+	*h = splitList(val)
+	return nil
+}
+
+// Headers holds extra request headers by name. As a flag a header is given as "Name: value",
+// multiple headers are given by repeating the flag or separated by newlines.
+type Headers map[string]string
+
+func (h Headers) String() string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = name + ": " + h[name]
+	}
+	return strings.Join(headers, ", ")
+}
+
+// headersFlag sets the headers of one configuration source. The first value of a source replaces
+// the headers of the sources of lower precedence, the following values are added to them.
+type headersFlag struct {
+	headers *Headers
+	set     bool
+}
+
+func (f *headersFlag) String() string {
+	if f.headers == nil {
+		return ""
+	}
+	return f.headers.String()
+}
+
+func (f *headersFlag) Set(val string) error {
+	parsed := make(Headers)
+	for _, line := range strings.Split(val, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid header %q, expected Name: value", line)
+		}
+		parsed[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+
+	if !f.set {
+		*f.headers = make(Headers)
+		f.set = true
+	}
+	for name, value := range parsed {
+		(*f.headers)[name] = value
+	}
+	return nil
+}
+
+// JobsConfig holds the settings of the asynchronous analysis jobs
+type JobsConfig struct {
+	Workers   int
+	QueueSize int
+	ResultTTL time.Duration
+}
+
+// CrawlConfig holds the limits of the site crawls
+type CrawlConfig struct {
+	MaxDepth    int
+	MaxPages    int
+	Concurrency int
+}
+
+// RobotsConfig holds how the robots.txt of the hosts is followed
+type RobotsConfig struct {
+	Mode          RobotsMode
+	MaxCrawlDelay time.Duration
+}
+
+// RobotsMode is a mode of the robots.txt policy, see the utils.Robots constants
+type RobotsMode string
+
+func (m *RobotsMode) String() string {
+	return string(*m)
+}
+
+// Set replaces the mode with the given one
+func (m *RobotsMode) Set(val string) error {
+	val = strings.TrimSpace(val)
+	if !slices.Contains(utils.RobotsModes, val) {
+		return fmt.Errorf("invalid robots mode %q, expected one of %s", val, strings.Join(utils.RobotsModes, ", "))
+	}
+	*m = RobotsMode(val)
+	return nil
+}
+
+// PolicyConfig holds the policy the analyzed pages are checked against
+type PolicyConfig struct {
+	// File is the path of the JSON policy file, empty to not check the pages
+	File string
+}
+
+// Default returns the configuration used when no flags or environment variables are set
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Analysis: AnalysisConfig{
+			Timeout:             2 * time.Minute,
+			LinkConcurrency:     analyzer.DefaultMaxConcurrency,
+			LinkHostConcurrency: analyzer.DefaultPerHostConcurrency,
+			LinkStatusStates:    utils.DefaultLinkStatusStates(),
+			LinkMaxRetryAfter:   utils.DefaultMaxRetryAfter,
+		},
+		Retry: RetryConfig{
+			MaxAttempts:  utils.DefaultMaxAttempts,
+			BaseDelay:    utils.DefaultRetryBaseDelay,
+			MaxDelay:     utils.DefaultRetryMaxDelay,
+			ErrorClasses: utils.DefaultRetryableErrorClasses(),
+			StatusCodes:  utils.DefaultRetryableStatusCodes(),
+		},
+		HTTP: HTTPConfig{
+			ConnectTimeout:      utils.DefaultConnectTimeout,
+			ReadTimeout:         utils.DefaultReadTimeout,
+			FetchTimeout:        utils.DefaultFetchTimeout,
+			LinkCheckTimeout:    utils.DefaultLinkCheckTimeout,
+			UserAgent:           utils.DefaultUserAgent,
+			Headers:             Headers{},
+			MaxIdleConns:        utils.DefaultMaxIdleConns,
+			MaxIdleConnsPerHost: utils.DefaultMaxIdleConnsPerHost,
+			MaxRedirects:        utils.DefaultMaxRedirects,
+			MaxBodySize:         utils.DefaultMaxBodySize,
+		},
+		Jobs: JobsConfig{
+			Workers:   jobs.DefaultWorkers,
+			QueueSize: jobs.DefaultQueueSize,
+			ResultTTL: jobs.DefaultResultTTL,
+		},
+		Crawl: CrawlConfig{
+			MaxDepth:    crawler.DefaultMaxDepth,
+			MaxPages:    crawler.DefaultMaxPages,
+			Concurrency: crawler.DefaultConcurrency,
+		},
+		Robots: RobotsConfig{
+			Mode:          utils.RobotsSkip,
+			MaxCrawlDelay: utils.DefaultMaxCrawlDelay,
+		},
+	}
+}
+
+// configFlag is the flag of the path of the JSON configuration file. It can also be set with
+// the ANALYZER_CONFIG environment variable.
+const configFlag = "config"
+
+// Load builds the configuration from the defaults, the configuration file, the environment
+// variables and the command line arguments, in increasing order of precedence
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
+
+// LoadCommand builds the configuration like Load from the arguments of a command. bind defines
+// the flags of the command, which are not configuration settings, on the flag set of the
+// configuration. The arguments after the flags are returned.
+func LoadCommand(args []string, bind func(fs *flag.FlagSet)) (*Config, []string, error) {
+	return loadCommand(args, os.LookupEnv, bind)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg, _, err := loadCommand(args, lookupEnv, nil)
+	return cfg, err
+}
+
+func loadCommand(args []string, lookupEnv func(string) (string, bool), bind func(fs *flag.FlagSet)) (*Config, []string, error) {
+	cfg := Default()
+
+	path, _ := lookupEnv(EnvName(configFlag))
+	if val, ok := configFlagValue(args, bind); ok {
+		path = val
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(lookupEnv); err != nil {
+		return nil, nil, err
+	}
+
+	fs := cfg.flagSet()
+	if bind != nil {
+		bind(fs)
+	}
+	fs.String(configFlag, path, "path of the JSON configuration file")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// configFlagValue returns the path of the configuration file given in the arguments. It is
+// needed before the flags are applied, as the file has a lower precedence.
+func configFlagValue(args []string, bind func(fs *flag.FlagSet)) (string, bool) {
+	fs := Default().flagSet()
+	if bind != nil {
+		bind(fs)
+	}
+	fs.SetOutput(io.Discard)
+	path := fs.String(configFlag, "", "")
+
+	// The invalid arguments are reported when the flags are applied
+	if err := fs.Parse(args); err != nil {
+		return "", false
+	}
+
+	var ok bool
+	fs.Visit(func(f *flag.Flag) {
+		ok = ok || f.Name == configFlag
+	})
+	return *path, ok
+}
+
+// loadFile applies the settings of the JSON configuration file. The keys of the file are the
+// flag names and the values are strings, numbers, booleans or lists of them.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the configuration file: %w", err)
+	}
+
+	var settings map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&settings); err != nil {
+		return fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fs := c.flagSet()
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q in %s", name, path)
+		}
+		val, err := settingValue(settings[name])
+		if err != nil {
+			return fmt.Errorf("invalid value for %s in %s: %v", name, path, err)
+		}
+		if err := fs.Set(name, val); err != nil {
+			return fmt.Errorf("invalid value %q for %s in %s: %v", val, name, path, err)
+		}
+	}
+	return nil
+}
+
+// settingValue returns the flag value of a setting of the configuration file. The items of a
+// list are separated by newlines.
+func settingValue(setting any) (string, error) {
+	switch val := setting.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			if _, ok := item.([]any); ok {
+				return "", errors.New("nested lists are not supported")
+			}
+			str, err := settingValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = str
+		}
+		return strings.Join(items, "\n"), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", setting)
+}
+
+func (c *Config) loadEnv(lookupEnv func(string) (string, bool)) error {
+	fs := c.flagSet()
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := EnvName(f.Name)
+		if val, ok := lookupEnv(name); ok && err == nil {
+			if setErr := fs.Set(f.Name, val); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %v", val, name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// flagSet returns a flag set bound to the configuration. Each configuration source is applied
+// with its own flag set.
+func (c *Config) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("webpage-analyzer", flag.ContinueOnError)
+	c.bindFlags(fs)
+	return fs
+}
+
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Server.Addr, "addr", c.Server.Addr, "address the HTTP server listens on")
+	fs.DurationVar(&c.Server.ShutdownTimeout, "shutdown-timeout", c.Server.ShutdownTimeout, "how long to wait for in-flight requests on shutdown")
+
+	fs.DurationVar(&c.Analysis.Timeout, "analysis-timeout", c.Analysis.Timeout, "maximum duration of a single analysis, 0 for no limit")
+	fs.IntVar(&c.Analysis.LinkConcurrency, "link-concurrency", c.Analysis.LinkConcurrency, "maximum number of links checked at the same time")
+	fs.IntVar(&c.Analysis.LinkHostConcurrency, "link-host-concurrency", c.Analysis.LinkHostConcurrency, "maximum number of links of the same host checked at the same time")
+	fs.DurationVar(&c.Analysis.LinkHostDelay, "link-host-delay", c.Analysis.LinkHostDelay, "minimum delay between two link checks of the same host")
+	fs.Var(c.Analysis.LinkStatusStates, "link-status-states", "states of the checked links by status code as code=state pairs, the states are ok, broken, restricted and rate_limited")
+	fs.DurationVar(&c.Analysis.LinkMaxRetryAfter, "link-max-retry-after", c.Analysis.LinkMaxRetryAfter, "longest Retry-After delay a link check waits for before retrying")
+	fs.Var(&c.Analysis.DisabledChecks, "disable-checks", "comma separated IDs of the checks the analyzed pages are not checked against")
+
+	fs.IntVar(&c.Retry.MaxAttempts, "retry-max-attempts", c.Retry.MaxAttempts, "maximum number of attempts of a page fetch or link check, 1 disables the retries")
+	fs.DurationVar(&c.Retry.BaseDelay, "retry-base-delay", c.Retry.BaseDelay, "delay before the first retry, doubled for every following retry")
+	fs.DurationVar(&c.Retry.MaxDelay, "retry-max-delay", c.Retry.MaxDelay, "maximum delay between two attempts")
+	fs.Var(&c.Retry.ErrorClasses, "retry-error-classes", "comma separated error classes of the failed requests that are retried")
+	fs.Var(&c.Retry.StatusCodes, "retry-status-codes", "comma separated status codes of the responses that are retried")
+
+	fs.DurationVar(&c.HTTP.ConnectTimeout, "http-connect-timeout", c.HTTP.ConnectTimeout, "maximum time to establish a connection")
+	fs.DurationVar(&c.HTTP.ReadTimeout, "http-read-timeout", c.HTTP.ReadTimeout, "maximum time to wait for the response headers")
+	fs.DurationVar(&c.HTTP.FetchTimeout, "http-fetch-timeout", c.HTTP.FetchTimeout, "maximum duration of a page fetch attempt")
+	fs.DurationVar(&c.HTTP.LinkCheckTimeout, "http-link-timeout", c.HTTP.LinkCheckTimeout, "maximum duration of a link check request")
+	fs.StringVar(&c.HTTP.UserAgent, "http-user-agent", c.HTTP.UserAgent, "User-Agent sent with every request")
+	fs.Var(&headersFlag{headers: &c.HTTP.Headers}, "http-header", "extra request header as \"Name: value\", can be repeated")
+	fs.StringVar(&c.HTTP.Proxy, "http-proxy", c.HTTP.Proxy, "URL of the HTTP(S) proxy, defaults to the HTTP_PROXY and HTTPS_PROXY environment variables")
+	fs.StringVar(&c.HTTP.CABundle, "http-ca-bundle", c.HTTP.CABundle, "path of a PEM file with additional trusted certificates")
+	fs.BoolVar(&c.HTTP.Insecure, "http-insecure", c.HTTP.Insecure, "disable the verification of the server certificates, for staging environments only")
+	fs.IntVar(&c.HTTP.MaxIdleConns, "http-max-idle-conns", c.HTTP.MaxIdleConns, "maximum number of idle connections kept for reuse")
+	fs.IntVar(&c.HTTP.MaxIdleConnsPerHost, "http-max-idle-conns-per-host", c.HTTP.MaxIdleConnsPerHost, "maximum number of idle connections kept for reuse per host")
+	fs.IntVar(&c.HTTP.MaxRedirects, "http-max-redirects", c.HTTP.MaxRedirects, "maximum number of redirects followed by a request")
+	fs.Int64Var(&c.HTTP.MaxBodySize, "http-max-body-size", c.HTTP.MaxBodySize, "maximum decoded size of a fetched page in bytes")
+	fs.Var(&c.HTTP.AllowHosts, "http-allow-hosts", "comma separated hosts, IP addresses and CIDR ranges that may be requested although they are private or reserved")
+	fs.Var(&c.HTTP.DenyHosts, "http-deny-hosts", "comma separated hosts, IP addresses and CIDR ranges that are never requested")
+
+	fs.IntVar(&c.Jobs.Workers, "job-workers", c.Jobs.Workers, "number of analysis jobs run concurrently")
+	fs.IntVar(&c.Jobs.QueueSize, "job-queue-size", c.Jobs.QueueSize, "maximum number of analysis jobs waiting in the queue")
+	fs.DurationVar(&c.Jobs.ResultTTL, "job-result-ttl", c.Jobs.ResultTTL, "how long the results of finished jobs are kept")
+
+	fs.IntVar(&c.Crawl.MaxDepth, "crawl-max-depth", c.Crawl.MaxDepth, "maximum number of links followed from the seed of a crawl, 0 crawls the seed only")
+	fs.IntVar(&c.Crawl.MaxPages, "crawl-max-pages", c.Crawl.MaxPages, "maximum number of pages analyzed by a crawl")
+	fs.IntVar(&c.Crawl.Concurrency, "crawl-concurrency", c.Crawl.Concurrency, "number of pages of a crawl analyzed concurrently")
+
+	fs.Var(&c.Robots.Mode, "robots", "how robots.txt is followed: off, report to report the disallowed URLs but request them, or skip to report them without requesting them")
+	fs.DurationVar(&c.Robots.MaxCrawlDelay, "robots-max-crawl-delay", c.Robots.MaxCrawlDelay, "longest Crawl-delay of robots.txt waited between two requests to a host")
+
+	fs.StringVar(&c.Policy.File, "policy", c.Policy.File, "JSON policy file the analyzed pages are checked against")
+}
+
+// splitList splits a list separated by commas or newlines and drops the empty items
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseStatusCode(val string) (int, error) {
+	code, err := strconv.Atoi(val)
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", val)
+	}
+	return code, nil
+}
+
+// EnvName returns the environment variable name of the given flag
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
@@ -0,0 +1,476 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name              string
+		args              []string
+		env               map[string]string
+		expectedAddr      string
+		expectedWorkers   int
+		expectedResultTTL time.Duration
+		expectedStates    string
+		expectedAllow     string
+		expectedRobots    string
+		hasError          bool
+	}{
+		{
+			name:              "Defaults",
+			expectedAddr:      ":8080",
+			expectedWorkers:   4,
+			expectedResultTTL: 10 * time.Minute,
+			expectedStates:    "401=restricted,403=restricted,429=rate_limited",
+			expectedRobots:    "skip",
+		},
+		{
+			name:              "Environment variables",
+			env:               map[string]string{"ANALYZER_ADDR": ":9090", "ANALYZER_JOB_WORKERS": "8"},
+			expectedAddr:      ":9090",
+			expectedWorkers:   8,
+			expectedResultTTL: 10 * time.Minute,
+		},
+		{
+			name:              "Flags take precedence over environment variables",
+			args:              []string{"-job-workers", "2", "-job-result-ttl", "30s"},
+			env:               map[string]string{"ANALYZER_JOB_WORKERS": "8"},
+			expectedAddr:      ":8080",
+			expectedWorkers:   2,
+			expectedResultTTL: 30 * time.Second,
+		},
+		{
+			name:              "Link status states from the environment",
+			env:               map[string]string{"ANALYZER_LINK_STATUS_STATES": "403=broken"},
+			expectedAddr:      ":8080",
+			expectedWorkers:   4,
+			expectedResultTTL: 10 * time.Minute,
+			expectedStates:    "403=broken",
+		},
+		{
+			name:              "Allowed hosts",
+			args:              []string{"-http-allow-hosts", "*.corp.example, 10.20.0.0/16"},
+			expectedAddr:      ":8080",
+			expectedWorkers:   4,
+			expectedResultTTL: 10 * time.Minute,
+			expectedAllow:     "*.corp.example,10.20.0.0/16",
+		},
+		{
+			name:              "Robots mode",
+			args:              []string{"-robots", "report"},
+			expectedAddr:      ":8080",
+			expectedWorkers:   4,
+			expectedResultTTL: 10 * time.Minute,
+			expectedRobots:    "report",
+		},
+		{
+			name:     "Invalid robots mode",
+			args:     []string{"-robots", "ignore"},
+			hasError: true,
+		},
+		{
+			name:     "Invalid link status states",
+			args:     []string{"-link-status-states", "403=hidden"},
+			hasError: true,
+		},
+		{
+			name:     "Invalid environment variable",
+			env:      map[string]string{"ANALYZER_JOB_WORKERS": "many"},
+			hasError: true,
+		},
+		{
+			name:     "Unknown flag",
+			args:     []string{"-unknown"},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				val, ok := test.env[name]
+				return val, ok
+			}
+
+			cfg, err := load(test.args, lookupEnv)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if cfg.Server.Addr != test.expectedAddr {
+				t.Errorf("Expected address '%s', got '%s'", test.expectedAddr, cfg.Server.Addr)
+			}
+			if cfg.Jobs.Workers != test.expectedWorkers {
+				t.Errorf("Expected workers '%d', got '%d'", test.expectedWorkers, cfg.Jobs.Workers)
+			}
+			if cfg.Jobs.ResultTTL != test.expectedResultTTL {
+				t.Errorf("Expected result TTL '%s', got '%s'", test.expectedResultTTL, cfg.Jobs.ResultTTL)
+			}
+			if cfg.HTTP.AllowHosts.String() != test.expectedAllow {
+				t.Errorf("Expected allowed hosts '%s', got '%s'", test.expectedAllow, cfg.HTTP.AllowHosts.String())
+			}
+			if test.expectedRobots != "" && string(cfg.Robots.Mode) != test.expectedRobots {
+				t.Errorf("Expected robots mode '%s', got '%s'", test.expectedRobots, cfg.Robots.Mode)
+			}
+			if test.expectedStates != "" && cfg.Analysis.LinkStatusStates.String() != test.expectedStates {
+				t.Errorf("Expected link status states '%s', got '%s'", test.expectedStates, cfg.Analysis.LinkStatusStates)
+			}
+		})
+	}
+}
+
+func TestLinkStatusStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "Valid pairs",
+			val:      "429=rate_limited, 403=broken",
+			expected: "403=broken,429=rate_limited",
+		},
+		{
+			name:     "Empty list",
+			val:      "",
+			expected: "",
+		},
+		{
+			name:     "Missing state",
+			val:      "403",
+			hasError: true,
+		},
+		{
+			name:     "Invalid status code",
+			val:      "forbidden=broken",
+			hasError: true,
+		},
+		{
+			name:     "Invalid state",
+			val:      "403=hidden",
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			states := LinkStatusStates{401: "restricted"}
+
+			err := states.Set(test.val)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if states.String() != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, states.String())
+			}
+		})
+	}
+}
+
+func TestRetryLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		val      string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "Status codes",
+			flag:     "-retry-status-codes",
+			val:      "500, 502",
+			expected: "500,502",
+		},
+		{
+			name:     "No status codes",
+			flag:     "-retry-status-codes",
+			val:      "",
+			expected: "",
+		},
+		{
+			name:     "Invalid status code",
+			flag:     "-retry-status-codes",
+			val:      "5xx",
+			hasError: true,
+		},
+		{
+			name:     "Error classes",
+			flag:     "-retry-error-classes",
+			val:      "timeout,connection_refused",
+			expected: "timeout,connection_refused",
+		},
+		{
+			name:     "Invalid error class",
+			flag:     "-retry-error-classes",
+			val:      "flaky",
+			hasError: true,
+		},
+		{
+			name:     "Status error class",
+			flag:     "-retry-error-classes",
+			val:      "timeout,http_5xx",
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := load([]string{test.flag, test.val}, func(string) (string, bool) { return "", false })
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			res := cfg.Retry.ErrorClasses.String()
+			if test.flag == "-retry-status-codes" {
+				res = cfg.Retry.StatusCodes.String()
+			}
+			if res != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestDisabledChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      string
+		expected string
+		hasError bool
+	}{
+		{name: "Checks", val: "h1, noindex", expected: "h1,noindex"},
+		{name: "No checks", val: "", expected: ""},
+		{name: "Unknown check", val: "h1,spelling", hasError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := load([]string{"-disable-checks", test.val}, func(string) (string, bool) { return "", false })
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if res := cfg.Analysis.DisabledChecks.String(); res != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	valid := writeFile("valid.json", `{
+		"addr": ":7070",
+		"job-workers": 6,
+		"http-insecure": true,
+		"http-user-agent": "nightly-report",
+		"http-header": ["X-Team: web", "Authorization: Bearer token"],
+		"retry-status-codes": [500, 502]
+	}`)
+	unknown := writeFile("unknown.json", `{"workers": 6}`)
+	invalid := writeFile("invalid.json", `{"job-workers": "many"}`)
+
+	tests := []struct {
+		name              string
+		args              []string
+		env               map[string]string
+		expectedAddr      string
+		expectedWorkers   int
+		expectedUserAgent string
+		expectedHeaders   string
+		hasError          bool
+	}{
+		{
+			name:              "File given as a flag",
+			args:              []string{"-config", valid},
+			expectedAddr:      ":7070",
+			expectedWorkers:   6,
+			expectedUserAgent: "nightly-report",
+			expectedHeaders:   "Authorization: Bearer token, X-Team: web",
+		},
+		{
+			name:              "File given as an environment variable",
+			env:               map[string]string{"ANALYZER_CONFIG": valid},
+			expectedAddr:      ":7070",
+			expectedWorkers:   6,
+			expectedUserAgent: "nightly-report",
+			expectedHeaders:   "Authorization: Bearer token, X-Team: web",
+		},
+		{
+			name:              "Environment variables and flags take precedence over the file",
+			args:              []string{"-config", valid, "-addr", ":9090", "-http-header", "X-Run: 1", "-http-header", "X-Job: 2"},
+			env:               map[string]string{"ANALYZER_JOB_WORKERS": "8", "ANALYZER_HTTP_HEADER": "X-Env: 1"},
+			expectedAddr:      ":9090",
+			expectedWorkers:   8,
+			expectedUserAgent: "nightly-report",
+			expectedHeaders:   "X-Job: 2, X-Run: 1",
+		},
+		{
+			name:     "Missing file",
+			args:     []string{"-config", filepath.Join(dir, "missing.json")},
+			hasError: true,
+		},
+		{
+			name:     "Unknown setting",
+			args:     []string{"-config", unknown},
+			hasError: true,
+		},
+		{
+			name:     "Invalid value",
+			args:     []string{"-config", invalid},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				val, ok := test.env[name]
+				return val, ok
+			}
+
+			cfg, err := load(test.args, lookupEnv)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if cfg.Server.Addr != test.expectedAddr {
+				t.Errorf("Expected address '%s', got '%s'", test.expectedAddr, cfg.Server.Addr)
+			}
+			if cfg.Jobs.Workers != test.expectedWorkers {
+				t.Errorf("Expected workers '%d', got '%d'", test.expectedWorkers, cfg.Jobs.Workers)
+			}
+			if cfg.HTTP.UserAgent != test.expectedUserAgent {
+				t.Errorf("Expected user agent '%s', got '%s'", test.expectedUserAgent, cfg.HTTP.UserAgent)
+			}
+			if cfg.HTTP.Headers.String() != test.expectedHeaders {
+				t.Errorf("Expected headers '%s', got '%s'", test.expectedHeaders, cfg.HTTP.Headers)
+			}
+			if !cfg.HTTP.Insecure {
+				t.Error("Expected the insecure mode of the file")
+			}
+			if cfg.Retry.StatusCodes.String() != "500,502" {
+				t.Errorf("Expected retry status codes '500,502', got '%s'", cfg.Retry.StatusCodes.String())
+			}
+		})
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"robots": "off"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedFormat string
+		expectedRobots string
+		expectedArgs   []string
+		hasError       bool
+	}{
+		{
+			name:           "Command and configuration flags",
+			args:           []string{"-format", "json", "-robots", "report", "https://example.com/", "https://example.org/"},
+			expectedFormat: "json",
+			expectedRobots: "report",
+			expectedArgs:   []string{"https://example.com/", "https://example.org/"},
+		},
+		{
+			name:           "Configuration file given after a command flag",
+			args:           []string{"-format", "json", "-config", path, "https://example.com/"},
+			expectedFormat: "json",
+			expectedRobots: "off",
+			expectedArgs:   []string{"https://example.com/"},
+		},
+		{
+			name:           "No arguments",
+			expectedFormat: "text",
+			expectedRobots: "skip",
+		},
+		{
+			name:     "Unknown flag",
+			args:     []string{"-unknown", "https://example.com/"},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var format string
+			bind := func(fs *flag.FlagSet) {
+				fs.SetOutput(io.Discard)
+				fs.StringVar(&format, "format", "text", "output format")
+			}
+
+			cfg, args, err := loadCommand(test.args, func(string) (string, bool) { return "", false }, bind)
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if format != test.expectedFormat {
+				t.Errorf("Expected format '%s', got '%s'", test.expectedFormat, format)
+			}
+			if string(cfg.Robots.Mode) != test.expectedRobots {
+				t.Errorf("Expected robots mode '%s', got '%s'", test.expectedRobots, cfg.Robots.Mode)
+			}
+			if !reflect.DeepEqual(args, test.expectedArgs) {
+				t.Errorf("Expected arguments %v, got %v", test.expectedArgs, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+var utilsInstance utils.UtilProvider = &utils.Utils{}
+
+// SetUtils sets the helpers used to fetch the sitemaps
+func SetUtils(u utils.UtilProvider) {
+	utilsInstance = u
+}
+
+// Default settings used for the zero values of the Crawler limits
+const (
+	DefaultMaxDepth    = 2
+	DefaultMaxPages    = 50
+	DefaultConcurrency = 2
+)
+
+// Crawler analyzes the pages of a site, starting from a seed URL and following the internal
+// links of the analyzed pages breadth-first
+type Crawler struct {
+	Analyzer analyzer.PageAnalyzer
+	// MaxDepth is the number of links followed from the seed to reach a page. Zero crawls
+	// the seed only.
+	MaxDepth int
+	// MaxPages limits the number of analyzed pages. Zero means DefaultMaxPages.
+	MaxPages int
+	// Concurrency limits the number of pages analyzed at the same time. Zero means
+	// DefaultConcurrency.
+	Concurrency int
+	// Policy is checked against each analyzed page, nil to not check the pages
+	Policy *policy.Policy
+}
+
+// Page is the analysis of a crawled page
+type Page struct {
+	// URL is the normalized URL the page was requested with
+	URL string `json:"url"`
+	// Depth is the number of links followed from the seed to reach the page
+	Depth int `json:"depth"`
+	// Referrer is the first crawled page linking to the page, empty for the seed
+	Referrer string           `json:"referrer,omitempty"`
+	Result   *analyzer.Result `json:"result"`
+	// Policy is the check of the page against the policy of the crawler, nil without a policy
+	// or when robots.txt disallows the page
+	Policy *policy.Report `json:"policy,omitempty"`
+}
+
+// Result is the outcome of a crawl
+type Result struct {
+	Seed string `json:"seed"`
+	// Pages lists the crawled pages by depth, in the order they were discovered
+	Pages   []Page  `json:"pages"`
+	Summary Summary `json:"summary"`
+	// Sitemap is the check of the sitemap URLs of a sitemap crawl, nil for a link crawl
+	Sitemap *SitemapReport `json:"sitemap,omitempty"`
+}
+
+// Crawl analyzes the seed page and the pages of the same site it links to, up to the depth
+// and page limits. The links are deduplicated on their normalized URL and the pages of the
+// hosts other than the host of the seed, or of the page it redirects to, are not crawled.
+// The pages share their link checks, and the links to the fetched pages are not checked again.
+// When the context is canceled the pages analyzed so far are returned.
+func (c *Crawler) Crawl(ctx context.Context, seed string) *Result {
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	res := &Result{Seed: seed, Pages: []Page{}}
+	skipped := 0
+	ctx = analyzer.WithLinkCache(ctx, analyzer.NewLinkCache(NormalizeURL))
+
+	start, err := NormalizeURL(seed)
+	if err != nil {
+		// The analyzer reports why the seed cannot be fetched
+		start = seed
+	}
+	// The hosts of the site, which are the host of the seed and of the page it redirects to
+	hosts := map[string]bool{hostOf(start): true}
+	visited := map[string]bool{start: true}
+
+	level := []Page{{URL: start}}
+	for depth := 0; len(level) > 0 && ctx.Err() == nil; depth++ {
+		if remaining := maxPages - len(res.Pages); len(level) > remaining {
+			skipped += len(level) - remaining
+			level = level[:remaining]
+		}
+		c.analyzeAll(ctx, level)
+
+		var next []Page
+		for _, page := range level {
+			res.Pages = append(res.Pages, page)
+			if page.Result.FinalURL == "" {
+				continue
+			}
+
+			final, err := NormalizeURL(page.Result.FinalURL)
+			if err != nil {
+				continue
+			}
+			visited[final] = true
+			if depth == 0 {
+				hosts[hostOf(final)] = true
+			}
+
+			if depth >= c.MaxDepth || page.Result.StageFailed() {
+				continue
+			}
+			for _, link := range page.Result.InternalLinks {
+				normalized, err := NormalizeURL(link)
+				if err != nil || visited[normalized] || !hosts[hostOf(normalized)] {
+					continue
+				}
+				visited[normalized] = true
+				next = append(next, Page{URL: normalized, Depth: depth + 1, Referrer: page.URL})
+			}
+		}
+		level = next
+	}
+
+	res.Summary = summarize(res.Pages)
+	res.Summary.SkippedPages = skipped
+	return res
+}
+
+// analyzeAll analyzes the pages concurrently and stores the results, and their check against
+// the policy, in them
+func (c *Crawler) analyzeAll(ctx context.Context, pages []Page) {
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range pages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(page *Page) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			page.Result = c.Analyzer.AnalyzeContext(ctx, page.URL, nil)
+			if c.Policy != nil && page.Result.ErrorCode != analyzer.ErrCodeRobotsDisallowed {
+				page.Policy = c.Policy.Evaluate(page.Result)
+			}
+		}(&pages[i])
+	}
+	wg.Wait()
+}
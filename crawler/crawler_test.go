@@ -0,0 +1,299 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// sitePage returns the result of a page that was analyzed successfully
+func sitePage(finalURL, title string, internalLinks ...string) *analyzer.Result {
+	return &analyzer.Result{
+		FinalURL:      finalURL,
+		Title:         title,
+		HeadingsCount: map[string]int{"h1": 1},
+		InternalLinks: internalLinks,
+		Stages:        []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageOK}},
+	}
+}
+
+// failedPage returns the result of a page that could not be fetched
+func failedPage() *analyzer.Result {
+	return &analyzer.Result{
+		ErrorCode: analyzer.ErrCodeUpstreamStatus,
+		Stages:    []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	site := map[string]*analyzer.Result{
+		"https://example.com/": sitePage("https://example.com/", "Home",
+			"https://example.com/a",
+			"https://example.com/a#top",
+			"https://EXAMPLE.com:443/b",
+			"https://example.com/missing",
+			"https://example.com/",
+		),
+		"https://example.com/a":       sitePage("https://example.com/a", "A", "https://example.com/c", "https://example.com/b"),
+		"https://example.com/b":       sitePage("https://example.com/b", "B", "https://example.com/d"),
+		"https://example.com/c":       sitePage("https://example.com/c", "C"),
+		"https://example.com/d":       sitePage("https://example.com/d", "D"),
+		"https://example.com/missing": failedPage(),
+		"http://example.com/": sitePage("https://www.example.com/", "Home",
+			"https://www.example.com/about",
+		),
+		// Redirected to another site, its links are not followed
+		"https://www.example.com/about": sitePage("https://blog.example.net/about", "About", "https://blog.example.net/post"),
+	}
+
+	tests := []struct {
+		name            string
+		seed            string
+		maxDepth        int
+		maxPages        int
+		expectedPages   []string
+		expectedDepths  []int
+		expectedSkipped int
+		expectedFailed  []string
+	}{
+		{
+			name:           "Seed only",
+			seed:           "https://example.com",
+			maxDepth:       0,
+			expectedPages:  []string{"https://example.com/"},
+			expectedDepths: []int{0},
+			expectedFailed: []string{},
+		},
+		{
+			name:           "Links deduplicated",
+			seed:           "https://example.com",
+			maxDepth:       1,
+			expectedPages:  []string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/missing"},
+			expectedDepths: []int{0, 1, 1, 1},
+			expectedFailed: []string{"https://example.com/missing"},
+		},
+		{
+			name:     "Whole site",
+			seed:     "https://example.com/",
+			maxDepth: 5,
+			expectedPages: []string{
+				"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/missing",
+				"https://example.com/c", "https://example.com/d",
+			},
+			expectedDepths: []int{0, 1, 1, 1, 2, 2},
+			expectedFailed: []string{"https://example.com/missing"},
+		},
+		{
+			name:            "Page limit",
+			seed:            "https://example.com/",
+			maxDepth:        5,
+			maxPages:        2,
+			expectedPages:   []string{"https://example.com/", "https://example.com/a"},
+			expectedDepths:  []int{0, 1},
+			expectedSkipped: 3,
+			expectedFailed:  []string{},
+		},
+		{
+			name:           "Seed redirected to another host",
+			seed:           "http://example.com",
+			maxDepth:       2,
+			expectedPages:  []string{"http://example.com/", "https://www.example.com/about"},
+			expectedDepths: []int{0, 1},
+			expectedFailed: []string{},
+		},
+		{
+			name:           "Seed not found",
+			seed:           "https://example.com/missing",
+			maxDepth:       1,
+			expectedPages:  []string{"https://example.com/missing"},
+			expectedDepths: []int{0},
+			expectedFailed: []string{"https://example.com/missing"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+			var mu sync.Mutex
+			var analyzed []string
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), nil).DoAndReturn(
+				func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+					mu.Lock()
+					analyzed = append(analyzed, pageURL)
+					mu.Unlock()
+
+					res, ok := site[pageURL]
+					if !ok {
+						t.Errorf("Unexpected analysis of '%s'", pageURL)
+						return failedPage()
+					}
+					return res
+				}).AnyTimes()
+
+			c := &Crawler{Analyzer: mockAnalyzer, MaxDepth: test.maxDepth, MaxPages: test.maxPages}
+			res := c.Crawl(context.Background(), test.seed)
+
+			var pages []string
+			var depths []int
+			for _, page := range res.Pages {
+				pages = append(pages, page.URL)
+				depths = append(depths, page.Depth)
+			}
+			if !reflect.DeepEqual(pages, test.expectedPages) {
+				t.Errorf("Expected pages %v, got %v", test.expectedPages, pages)
+			}
+			if !reflect.DeepEqual(depths, test.expectedDepths) {
+				t.Errorf("Expected depths %v, got %v", test.expectedDepths, depths)
+			}
+
+			sort.Strings(analyzed)
+			expectedAnalyzed := append([]string(nil), test.expectedPages...)
+			sort.Strings(expectedAnalyzed)
+			if !reflect.DeepEqual(analyzed, expectedAnalyzed) {
+				t.Errorf("Expected the analysis of %v, got %v", expectedAnalyzed, analyzed)
+			}
+
+			if res.Summary.TotalPages != len(test.expectedPages) {
+				t.Errorf("Expected %d total pages, got %d", len(test.expectedPages), res.Summary.TotalPages)
+			}
+			if res.Summary.SkippedPages != test.expectedSkipped {
+				t.Errorf("Expected %d skipped pages, got %d", test.expectedSkipped, res.Summary.SkippedPages)
+			}
+			if !reflect.DeepEqual(res.Summary.FailedPages, test.expectedFailed) {
+				t.Errorf("Expected failed pages %v, got %v", test.expectedFailed, res.Summary.FailedPages)
+			}
+		})
+	}
+}
+
+func TestCrawlCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), "https://example.com/", nil).DoAndReturn(
+		func(context.Context, string, analyzer.ProgressFunc) *analyzer.Result {
+			cancel()
+			return sitePage("https://example.com/", "Home", "https://example.com/a")
+		})
+
+	c := &Crawler{Analyzer: mockAnalyzer, MaxDepth: 2}
+	res := c.Crawl(ctx, "https://example.com/")
+
+	if len(res.Pages) != 1 {
+		t.Errorf("Expected the pages analyzed before the cancellation, got %d pages", len(res.Pages))
+	}
+}
+
+func TestCrawlPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	private := &analyzer.Result{
+		RobotsDisallowed: true,
+		ErrorCode:        analyzer.ErrCodeRobotsDisallowed,
+		Stages:           []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+	}
+	site := map[string]*analyzer.Result{
+		"https://example.com/":        sitePage("https://example.com/", "Home", "https://example.com/a", "https://example.com/private"),
+		"https://example.com/a":       sitePage("https://example.com/a", ""),
+		"https://example.com/private": private,
+	}
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), nil).DoAndReturn(
+		func(_ context.Context, pageURL string, _ analyzer.ProgressFunc) *analyzer.Result {
+			return site[pageURL]
+		}).Times(3)
+
+	zero := 0
+	c := &Crawler{
+		Analyzer: mockAnalyzer,
+		MaxDepth: 1,
+		Policy:   &policy.Policy{Rules: []policy.Rule{{Metric: "missing_title", FailAbove: &zero}}},
+	}
+	res := c.Crawl(context.Background(), "https://example.com/")
+
+	statuses := make(map[string]string)
+	for _, page := range res.Pages {
+		if page.Policy != nil {
+			statuses[page.URL] = page.Policy.Status
+		}
+	}
+	expected := map[string]string{
+		"https://example.com/":  policy.StatusPass,
+		"https://example.com/a": policy.StatusFail,
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Expected policy statuses %v, got %v", expected, statuses)
+	}
+	if failed := []string{"https://example.com/a"}; !reflect.DeepEqual(res.Summary.PolicyFailedPages, failed) {
+		t.Errorf("Expected policy failed pages %v, got %v", failed, res.Summary.PolicyFailedPages)
+	}
+}
+
+func TestCrawlSharedLinkChecks(t *testing.T) {
+	var mu sync.Mutex
+	var external string
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/robots.txt":
+			http.NotFound(w, r)
+		case "/ext":
+			io.WriteString(w, "ext")
+		default:
+			fmt.Fprintf(w, `<!DOCTYPE html><html><head><title>Page</title></head><body>
+				<a href="/">Home</a><a href="/a">A</a><a href="/b">B</a><a href="%s/ext">Ext</a></body></html>`, external)
+		}
+	}))
+	defer server.Close()
+	// The same server on another host name, its links are external
+	external = strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	u, err := utils.New(utils.ClientConfig{AllowHosts: []string{"127.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer.SetUtils(u)
+	defer analyzer.SetUtils(&utils.Utils{})
+
+	c := &Crawler{Analyzer: &analyzer.Analyzer{}, MaxDepth: 1}
+	res := c.Crawl(context.Background(), server.URL+"/")
+
+	if len(res.Pages) != 3 {
+		t.Fatalf("Expected 3 crawled pages, got %d", len(res.Pages))
+	}
+	for _, page := range res.Pages {
+		if len(page.Result.Links) != 4 || page.Result.InAccessibleLinks != 0 {
+			t.Errorf("Expected 4 accessible links on '%s', got %+v", page.URL, page.Result.Links)
+		}
+	}
+
+	// The seed is fetched once and the links shared by the pages are checked once. The pages
+	// linked by the seed are checked before they are crawled.
+	expected := map[string]int{"/": 1, "/a": 2, "/b": 2, "/ext": 1}
+	for path, count := range expected {
+		if requests[path] != count {
+			t.Errorf("Expected %d requests of '%s', got %d", count, path, requests[path])
+		}
+	}
+}
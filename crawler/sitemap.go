@@ -0,0 +1,180 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// SitemapReport is the check of the URLs listed in the sitemaps of a sitemap crawl
+type SitemapReport struct {
+	// Sitemaps lists the sitemaps requested, given or discovered from robots.txt
+	Sitemaps []string `json:"sitemaps"`
+	// Files lists the sitemap files fetched, including the files listed by the sitemap indexes
+	Files []string `json:"files"`
+	// Errors lists the sitemap files that could not be fetched or parsed
+	Errors []utils.SitemapError `json:"errors"`
+	// TotalURLs is the number of distinct URLs listed in the sitemaps
+	TotalURLs int `json:"total_urls"`
+	// NonOKURLs lists the analyzed URLs that did not return a 200 response
+	NonOKURLs []FlaggedURL `json:"non_ok_urls"`
+	// RedirectedURLs lists the analyzed URLs that redirect to another URL
+	RedirectedURLs []FlaggedURL `json:"redirected_urls"`
+	// NoindexURLs lists the analyzed URLs that ask not to be indexed
+	NoindexURLs []FlaggedURL `json:"noindex_urls"`
+	// MissingURLs lists the internal links of the analyzed pages that are not in the sitemaps
+	MissingURLs []MissingURL `json:"missing_urls"`
+}
+
+// FlaggedURL is a sitemap URL whose analysis shows it should not be listed in the sitemap
+type FlaggedURL struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	FinalURL   string `json:"final_url,omitempty"`
+}
+
+// MissingURL is an internal link of the analyzed pages that is not listed in the sitemaps
+type MissingURL struct {
+	URL string `json:"url"`
+	// Pages lists the analyzed pages linking to it
+	Pages []string `json:"pages"`
+}
+
+// CrawlSitemap analyzes the URLs listed in the sitemap, following the sitemap index files,
+// up to the page limit. It returns an error if the sitemap cannot be fetched.
+func (c *Crawler) CrawlSitemap(ctx context.Context, sitemapURL string) (*Result, error) {
+	return c.crawlSitemaps(ctx, sitemapURL, []string{sitemapURL})
+}
+
+// CrawlSiteSitemaps analyzes the URLs listed in the sitemaps of the site, which are the
+// sitemaps listed in its robots.txt or its /sitemap.xml. It returns an error if none of them
+// can be fetched.
+func (c *Crawler) CrawlSiteSitemaps(ctx context.Context, siteURL string) (*Result, error) {
+	sitemaps, err := utilsInstance.DiscoverSitemaps(ctx, siteURL)
+	if err != nil {
+		return nil, err
+	}
+	return c.crawlSitemaps(ctx, siteURL, sitemaps)
+}
+
+// crawlSitemaps analyzes the URLs listed in the sitemaps, deduplicated on their normalized URL.
+// The pages share their link checks as in a site crawl.
+func (c *Crawler) crawlSitemaps(ctx context.Context, seed string, sitemaps []string) (*Result, error) {
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	report := &SitemapReport{
+		Sitemaps:       sitemaps,
+		Files:          []string{},
+		Errors:         []utils.SitemapError{},
+		NonOKURLs:      []FlaggedURL{},
+		RedirectedURLs: []FlaggedURL{},
+		NoindexURLs:    []FlaggedURL{},
+		MissingURLs:    []MissingURL{},
+	}
+
+	var firstErr error
+	listed := make(map[string]bool)
+	// The hosts of the sitemap URLs, the links to other hosts are not expected in the sitemaps
+	hosts := make(map[string]bool)
+	pages := []Page{}
+	for _, sitemapURL := range sitemaps {
+		sitemap, err := utilsInstance.FetchSitemap(ctx, sitemapURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			report.Errors = append(report.Errors, utils.SitemapError{URL: sitemapURL, Error: err.Error()})
+			continue
+		}
+		report.Files = append(report.Files, sitemap.Files...)
+		report.Errors = append(report.Errors, sitemap.Errors...)
+
+		for _, entry := range sitemap.URLs {
+			normalized, err := NormalizeURL(entry.Loc)
+			if err != nil {
+				// The analyzer reports why the URL cannot be fetched
+				normalized = entry.Loc
+			}
+			if listed[normalized] {
+				continue
+			}
+			listed[normalized] = true
+			hosts[hostOf(normalized)] = true
+			pages = append(pages, Page{URL: normalized})
+		}
+	}
+	if len(report.Files) == 0 {
+		return nil, firstErr
+	}
+
+	report.TotalURLs = len(pages)
+	skipped := 0
+	if len(pages) > maxPages {
+		skipped = len(pages) - maxPages
+		pages = pages[:maxPages]
+	}
+	c.analyzeAll(analyzer.WithLinkCache(ctx, analyzer.NewLinkCache(NormalizeURL)), pages)
+
+	res := &Result{Seed: seed, Pages: pages, Sitemap: report}
+	checkSitemap(report, res.Pages, listed, hosts)
+	res.Summary = summarize(res.Pages)
+	res.Summary.SkippedPages = skipped
+	return res, nil
+}
+
+// checkSitemap flags the analyzed sitemap URLs that are not 200 pages to index, and reports the
+// internal links of the analyzed pages that are not listed in the sitemaps
+func checkSitemap(report *SitemapReport, pages []Page, listed, hosts map[string]bool) {
+	missing := make(map[string]int)
+	for _, page := range pages {
+		res := page.Result
+		if res.ErrorCode == analyzer.ErrCodeRobotsDisallowed && res.StatusCode == 0 {
+			// Not requested, so there is nothing to check
+			continue
+		}
+
+		if res.StatusCode != http.StatusOK {
+			flagged := FlaggedURL{URL: page.URL, StatusCode: res.StatusCode}
+			if res.StatusCode == 0 {
+				flagged.ErrorCode = res.ErrorCode
+			}
+			report.NonOKURLs = append(report.NonOKURLs, flagged)
+		}
+		if len(res.Redirects) > 0 {
+			report.RedirectedURLs = append(report.RedirectedURLs, FlaggedURL{
+				URL:        page.URL,
+				StatusCode: res.Redirects[0].StatusCode,
+				FinalURL:   res.FinalURL,
+			})
+		}
+		if res.Noindex {
+			report.NoindexURLs = append(report.NoindexURLs, FlaggedURL{URL: page.URL, StatusCode: res.StatusCode})
+		}
+
+		if res.StageFailed() {
+			continue
+		}
+		for _, link := range res.InternalLinks {
+			normalized, err := NormalizeURL(link)
+			if err != nil || listed[normalized] || !hosts[hostOf(normalized)] {
+				continue
+			}
+			i, ok := missing[normalized]
+			if !ok {
+				i = len(report.MissingURLs)
+				missing[normalized] = i
+				report.MissingURLs = append(report.MissingURLs, MissingURL{URL: normalized})
+			}
+			report.MissingURLs[i].Pages = append(report.MissingURLs[i].Pages, page.URL)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+	utilsMocks "github.com/isurukdniss/webpage-analyzer/utils/mocks"
+)
+
+// okPage returns the result of a page served with a 200 response
+func okPage(finalURL string, internalLinks ...string) *analyzer.Result {
+	res := sitePage(finalURL, "Page", internalLinks...)
+	res.StatusCode = http.StatusOK
+	return res
+}
+
+func TestCrawlSitemap(t *testing.T) {
+	redirected := okPage("https://example.com/new")
+	redirected.Redirects = []utils.Redirect{{URL: "https://example.com/old", StatusCode: http.StatusMovedPermanently, Location: "https://example.com/new"}}
+	noindex := okPage("https://example.com/draft")
+	noindex.Noindex = true
+	notFound := failedPage()
+	notFound.StatusCode = http.StatusNotFound
+	unreachable := failedPage()
+	unreachable.ErrorCode = analyzer.ErrCodeDNSFailed
+
+	site := map[string]*analyzer.Result{
+		"https://example.com/": okPage("https://example.com/",
+			"https://example.com/about",
+			"https://example.com/unlisted#top",
+			"https://other.example.net/",
+		),
+		"https://example.com/about":    okPage("https://example.com/about", "https://example.com/unlisted", "https://example.com/"),
+		"https://example.com/old":      redirected,
+		"https://example.com/draft":    noindex,
+		"https://example.com/gone":     notFound,
+		"https://unreachable.example/": unreachable,
+	}
+	sitemap := &utils.Sitemap{
+		URLs: []utils.SitemapURL{
+			{Loc: "https://example.com"},
+			{Loc: "https://example.com/about"},
+			{Loc: "https://EXAMPLE.com/about#team"},
+			{Loc: "https://example.com/old"},
+			{Loc: "https://example.com/draft"},
+			{Loc: "https://example.com/gone"},
+			{Loc: "https://unreachable.example/"},
+		},
+		Files:  []string{"https://example.com/sitemap_index.xml", "https://example.com/sitemap.xml"},
+		Errors: []utils.SitemapError{{URL: "https://example.com/news.xml", Error: "unexpected status code: 404"}},
+	}
+
+	tests := []struct {
+		name             string
+		maxPages         int
+		expectedPages    []string
+		expectedSkipped  int
+		expectedNonOK    []FlaggedURL
+		expectedRedirect []FlaggedURL
+		expectedNoindex  []FlaggedURL
+		expectedMissing  []MissingURL
+	}{
+		{
+			name: "All sitemap URLs",
+			expectedPages: []string{
+				"https://example.com/", "https://example.com/about", "https://example.com/old",
+				"https://example.com/draft", "https://example.com/gone", "https://unreachable.example/",
+			},
+			expectedNonOK: []FlaggedURL{
+				{URL: "https://example.com/gone", StatusCode: http.StatusNotFound},
+				{URL: "https://unreachable.example/", ErrorCode: analyzer.ErrCodeDNSFailed},
+			},
+			expectedRedirect: []FlaggedURL{{URL: "https://example.com/old", StatusCode: http.StatusMovedPermanently, FinalURL: "https://example.com/new"}},
+			expectedNoindex:  []FlaggedURL{{URL: "https://example.com/draft", StatusCode: http.StatusOK}},
+			expectedMissing: []MissingURL{
+				{URL: "https://example.com/unlisted", Pages: []string{"https://example.com/", "https://example.com/about"}},
+			},
+		},
+		{
+			name:             "Page limit",
+			maxPages:         2,
+			expectedPages:    []string{"https://example.com/", "https://example.com/about"},
+			expectedSkipped:  4,
+			expectedNonOK:    []FlaggedURL{},
+			expectedRedirect: []FlaggedURL{},
+			expectedNoindex:  []FlaggedURL{},
+			expectedMissing: []MissingURL{
+				{URL: "https://example.com/unlisted", Pages: []string{"https://example.com/", "https://example.com/about"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockUtils := utilsMocks.NewMockUtilProvider(ctrl)
+			SetUtils(mockUtils)
+			defer SetUtils(&utils.Utils{})
+			mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+			mockUtils.EXPECT().FetchSitemap(gomock.Any(), "https://example.com/sitemap_index.xml").Return(sitemap, nil)
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), nil).DoAndReturn(
+				func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+					return site[pageURL]
+				}).Times(len(test.expectedPages))
+
+			c := &Crawler{Analyzer: mockAnalyzer, MaxPages: test.maxPages}
+			res, err := c.CrawlSitemap(context.Background(), "https://example.com/sitemap_index.xml")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var pages []string
+			for _, page := range res.Pages {
+				pages = append(pages, page.URL)
+			}
+			if !reflect.DeepEqual(pages, test.expectedPages) {
+				t.Errorf("Expected pages %v, got %v", test.expectedPages, pages)
+			}
+			if res.Summary.SkippedPages != test.expectedSkipped {
+				t.Errorf("Expected %d skipped pages, got %d", test.expectedSkipped, res.Summary.SkippedPages)
+			}
+
+			report := res.Sitemap
+			if report.TotalURLs != 6 {
+				t.Errorf("Expected 6 distinct sitemap URLs, got %d", report.TotalURLs)
+			}
+			if !reflect.DeepEqual(report.Files, sitemap.Files) || !reflect.DeepEqual(report.Errors, sitemap.Errors) {
+				t.Errorf("Expected the files and errors of the sitemap, got %v and %v", report.Files, report.Errors)
+			}
+			if !reflect.DeepEqual(report.NonOKURLs, test.expectedNonOK) {
+				t.Errorf("Expected non-200 URLs %v, got %v", test.expectedNonOK, report.NonOKURLs)
+			}
+			if !reflect.DeepEqual(report.RedirectedURLs, test.expectedRedirect) {
+				t.Errorf("Expected redirected URLs %v, got %v", test.expectedRedirect, report.RedirectedURLs)
+			}
+			if !reflect.DeepEqual(report.NoindexURLs, test.expectedNoindex) {
+				t.Errorf("Expected noindex URLs %v, got %v", test.expectedNoindex, report.NoindexURLs)
+			}
+			if !reflect.DeepEqual(report.MissingURLs, test.expectedMissing) {
+				t.Errorf("Expected missing URLs %v, got %v", test.expectedMissing, report.MissingURLs)
+			}
+		})
+	}
+}
+
+func TestCrawlSiteSitemaps(t *testing.T) {
+	notFound := &utils.StatusError{StatusCode: http.StatusNotFound}
+
+	tests := []struct {
+		name           string
+		fetchErrors    map[string]error
+		expectedErr    error
+		expectedErrors int
+		expectedPages  int
+	}{
+		{
+			name:          "All sitemaps fetched",
+			expectedPages: 2,
+		},
+		{
+			name:           "One sitemap missing",
+			fetchErrors:    map[string]error{"https://example.com/news.xml": notFound},
+			expectedErrors: 1,
+			expectedPages:  1,
+		},
+		{
+			name: "No sitemap fetched",
+			fetchErrors: map[string]error{
+				"https://example.com/sitemap.xml": notFound,
+				"https://example.com/news.xml":    errors.New("invalid sitemap"),
+			},
+			expectedErr: notFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockUtils := utilsMocks.NewMockUtilProvider(ctrl)
+			SetUtils(mockUtils)
+			defer SetUtils(&utils.Utils{})
+			mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+			sitemaps := map[string]string{
+				"https://example.com/sitemap.xml": "https://example.com/",
+				"https://example.com/news.xml":    "https://example.com/news/1",
+			}
+			mockUtils.EXPECT().DiscoverSitemaps(gomock.Any(), "https://example.com/").
+				Return([]string{"https://example.com/sitemap.xml", "https://example.com/news.xml"}, nil)
+			mockUtils.EXPECT().FetchSitemap(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, sitemapURL string) (*utils.Sitemap, error) {
+					if err := test.fetchErrors[sitemapURL]; err != nil {
+						return nil, err
+					}
+					return &utils.Sitemap{URLs: []utils.SitemapURL{{Loc: sitemaps[sitemapURL]}}, Files: []string{sitemapURL}}, nil
+				}).Times(2)
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), nil).DoAndReturn(
+				func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+					return okPage(pageURL)
+				}).Times(test.expectedPages)
+
+			c := &Crawler{Analyzer: mockAnalyzer}
+			res, err := c.CrawlSiteSitemaps(context.Background(), "https://example.com/")
+
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Errorf("Expected error %v, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if res.Seed != "https://example.com/" || len(res.Sitemap.Sitemaps) != 2 {
+				t.Errorf("Expected the crawl of the 2 sitemaps of the site, got seed '%s' and sitemaps %v", res.Seed, res.Sitemap.Sitemaps)
+			}
+			if len(res.Sitemap.Errors) != test.expectedErrors {
+				t.Errorf("Expected %d sitemap errors, got %v", test.expectedErrors, res.Sitemap.Errors)
+			}
+			if len(res.Pages) != test.expectedPages {
+				t.Errorf("Expected %d pages, got %d", test.expectedPages, len(res.Pages))
+			}
+		})
+	}
+}
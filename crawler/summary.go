@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+// Summary aggregates the results of the crawled pages
+type Summary struct {
+	TotalPages int `json:"total_pages"`
+	// FailedPages lists the pages that could not be fetched or parsed
+	FailedPages []string `json:"failed_pages"`
+	// DisallowedPages lists the pages disallowed by robots.txt, which are not analyzed when the
+	// disallowed URLs are skipped
+	DisallowedPages []string `json:"disallowed_pages"`
+	// SkippedPages is the number of discovered pages not crawled because of the page limit
+	SkippedPages int `json:"skipped_pages"`
+	// BrokenLinks lists the inaccessible links of all the pages, each with the pages linking to it
+	BrokenLinks        []BrokenLink `json:"broken_links"`
+	PagesMissingTitle  []string     `json:"pages_missing_title"`
+	PagesWithoutH1     []string     `json:"pages_without_h1"`
+	PagesWithLoginForm []string     `json:"pages_with_login_form"`
+	// PolicyFailedPages lists the pages failing the policy of the crawler, if any
+	PolicyFailedPages []string `json:"policy_failed_pages,omitempty"`
+}
+
+// BrokenLink is an inaccessible link found in the crawled pages
+type BrokenLink struct {
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	StatusCode int    `json:"status_code,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	// Pages lists the crawled pages linking to it
+	Pages []string `json:"pages"`
+}
+
+// summarize builds the summary of the crawled pages. The pages that failed are only listed
+// in FailedPages, or in DisallowedPages when they were skipped because of robots.txt.
+func summarize(pages []Page) Summary {
+	s := Summary{
+		TotalPages:         len(pages),
+		FailedPages:        []string{},
+		DisallowedPages:    []string{},
+		BrokenLinks:        []BrokenLink{},
+		PagesMissingTitle:  []string{},
+		PagesWithoutH1:     []string{},
+		PagesWithLoginForm: []string{},
+	}
+
+	broken := make(map[string]int)
+	for _, page := range pages {
+		res := page.Result
+		if page.Policy != nil && !page.Policy.Passed {
+			s.PolicyFailedPages = append(s.PolicyFailedPages, page.URL)
+		}
+		if res.RobotsDisallowed {
+			s.DisallowedPages = append(s.DisallowedPages, page.URL)
+		}
+		if res.StageFailed() {
+			if res.ErrorCode != analyzer.ErrCodeRobotsDisallowed {
+				s.FailedPages = append(s.FailedPages, page.URL)
+			}
+			continue
+		}
+
+		if res.Title == "" {
+			s.PagesMissingTitle = append(s.PagesMissingTitle, page.URL)
+		}
+		if res.HeadingsCount["h1"] == 0 {
+			s.PagesWithoutH1 = append(s.PagesWithoutH1, page.URL)
+		}
+		if res.HasLoginForm {
+			s.PagesWithLoginForm = append(s.PagesWithLoginForm, page.URL)
+		}
+
+		for _, link := range res.Links {
+			if link.Accessible {
+				continue
+			}
+			i, ok := broken[link.URL]
+			if !ok {
+				i = len(s.BrokenLinks)
+				broken[link.URL] = i
+				s.BrokenLinks = append(s.BrokenLinks, BrokenLink{
+					URL:        link.URL,
+					State:      link.State,
+					StatusCode: link.StatusCode,
+					ErrorClass: link.ErrorClass,
+				})
+			}
+			s.BrokenLinks[i].Pages = append(s.BrokenLinks[i].Pages, page.URL)
+		}
+	}
+	return s
+}
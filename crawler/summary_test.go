@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+func TestSummarize(t *testing.T) {
+	brokenLink := func(link string, statusCode int) analyzer.LinkReport {
+		return analyzer.LinkReport{LinkCheck: utils.LinkCheck{URL: link, State: utils.LinkStateBroken, StatusCode: statusCode}}
+	}
+
+	home := sitePage("https://example.com/", "Home")
+	home.HasLoginForm = true
+	home.Links = []analyzer.LinkReport{
+		{LinkCheck: utils.LinkCheck{URL: "https://example.com/a", Accessible: true, State: utils.LinkStateOK}},
+		brokenLink("https://example.com/gone", 404),
+		brokenLink("https://other.example/", 500),
+	}
+	untitled := sitePage("https://example.com/a", "")
+	untitled.HeadingsCount = map[string]int{"h2": 1}
+	untitled.Links = []analyzer.LinkReport{brokenLink("https://example.com/gone", 404)}
+
+	pages := []Page{
+		{URL: "https://example.com/", Result: home},
+		{URL: "https://example.com/a", Depth: 1, Result: untitled},
+		{URL: "https://example.com/missing", Depth: 1, Result: failedPage()},
+		{URL: "https://example.com/private", Depth: 1, Result: &analyzer.Result{
+			RobotsDisallowed: true,
+			ErrorCode:        analyzer.ErrCodeRobotsDisallowed,
+			Stages:           []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+		}},
+	}
+
+	expected := Summary{
+		TotalPages:      4,
+		FailedPages:     []string{"https://example.com/missing"},
+		DisallowedPages: []string{"https://example.com/private"},
+		BrokenLinks: []BrokenLink{
+			{
+				URL:        "https://example.com/gone",
+				State:      utils.LinkStateBroken,
+				StatusCode: 404,
+				Pages:      []string{"https://example.com/", "https://example.com/a"},
+			},
+			{
+				URL:        "https://other.example/",
+				State:      utils.LinkStateBroken,
+				StatusCode: 500,
+				Pages:      []string{"https://example.com/"},
+			},
+		},
+		PagesMissingTitle:  []string{"https://example.com/a"},
+		PagesWithoutH1:     []string{"https://example.com/a"},
+		PagesWithLoginForm: []string{"https://example.com/"},
+	}
+
+	if summary := summarize(pages); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+}
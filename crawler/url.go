@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// errNotCrawlable is returned for the URLs that are not absolute http or https URLs
+var errNotCrawlable = errors.New("not an absolute http or https URL")
+
+// defaultPorts are the ports dropped from the normalized URLs
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeURL returns the form of the URL used to deduplicate the crawled pages: the scheme
+// and the host are lower-cased, the default port, the user info and the fragment are removed
+// and an empty path is replaced with "/"
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok || u.Host == "" {
+		return "", errNotCrawlable
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	if host, port, err := net.SplitHostPort(u.Host); err == nil && port == defaultPorts[u.Scheme] {
+		u.Host = host
+		if strings.Contains(host, ":") {
+			u.Host = "[" + host + "]"
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.ForceQuery = false
+	u.User = nil
+
+	return u.String(), nil
+}
+
+// hostOf returns the host of a normalized URL
+func hostOf(normalized string) string {
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -5,4 +5,5 @@ go 1.21.3
 require (
 	go.uber.org/mock v0.4.0
 	golang.org/x/net v0.29.0
+	golang.org/x/text v0.18.0
 )
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+// APIVersionPrefix is the path prefix of the versioned JSON API
+const APIVersionPrefix = "/api/v1"
+
+// maxRequestBodySize limits the size of the JSON payloads accepted by the API
+const maxRequestBodySize = 1 << 20
+
+// Error codes returned by the API in addition to the analyzer error codes
+const (
+	errCodeInvalidRequest   = "invalid_request"
+	errCodeMethodNotAllowed = "method_not_allowed"
+)
+
+var policyInstance *policy.Policy
+
+// SetPolicy sets the policy the results of the analyze API are checked against, nil to not
+// check them
+func SetPolicy(p *policy.Policy) {
+	policyInstance = p
+}
+
+type analyzeRequest struct {
+	URL string `json:"url"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiResponse struct {
+	Result *analyzer.Result `json:"result,omitempty"`
+	Job    *jobs.Job        `json:"job,omitempty"`
+	Crawl  *crawler.Result  `json:"crawl,omitempty"`
+	// Policy is the check of the result against the policy, nil without a policy
+	Policy *policy.Report `json:"policy,omitempty"`
+	Error  *apiError      `json:"error,omitempty"`
+}
+
+// APIAnalyzeHandler performs the analysis of the URL given in the JSON request body
+// and writes the result, and its check against the policy, as JSON
+func APIAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only POST requests are supported.")
+		return
+	}
+
+	req, ok := decodeAnalyzeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	res := analyzerInstance.AnalyzeContext(r.Context(), req.URL, nil)
+
+	resp := apiResponse{Result: res}
+	if policyInstance != nil {
+		resp.Policy = policyInstance.Evaluate(res)
+	}
+	status := http.StatusOK
+	if res.ErrorCode != "" {
+		resp.Error = &apiError{Code: res.ErrorCode, Message: res.ErrorMessage}
+		// An interrupted link check returns the partial result with its error
+		if res.StageFailed() {
+			status = statusForErrorCode(res.ErrorCode)
+		}
+	}
+
+	writeJSON(w, status, resp)
+}
+
+// statusForErrorCode maps an analyzer error code to the HTTP status returned by the API
+func statusForErrorCode(code string) int {
+	switch code {
+	case analyzer.ErrCodeInvalidURL, analyzer.ErrCodeUnsupportedScheme:
+		return http.StatusBadRequest
+	case analyzer.ErrCodeBlockedAddress, analyzer.ErrCodeRobotsDisallowed:
+		return http.StatusForbidden
+	case analyzer.ErrCodeDNSFailed, analyzer.ErrCodeTLSFailed, analyzer.ErrCodeFetchFailed, analyzer.ErrCodeUpstreamStatus,
+		analyzer.ErrCodeTooManyRedirects, analyzer.ErrCodeRedirectLoop,
+		analyzer.ErrCodeBodyTooLarge, analyzer.ErrCodeReadFailed, analyzer.ErrCodeParseFailed, analyzer.ErrCodeInvalidSitemap:
+		return http.StatusBadGateway
+	case analyzer.ErrCodeTimeout:
+		return http.StatusGatewayTimeout
+	case analyzer.ErrCodeCanceled:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// decodeAnalyzeRequest decodes and validates an analyze request. When the request is
+// invalid an error response is written and false is returned.
+func decodeAnalyzeRequest(w http.ResponseWriter, r *http.Request) (analyzeRequest, bool) {
+	var req analyzeRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "Invalid request body: "+err.Error())
+		return req, false
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "The 'url' field is required.")
+		return req, false
+	}
+
+	return req, true
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errors.New("body is too large")
+		}
+		return errors.New("body must be a valid JSON object")
+	}
+
+	return nil
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, apiResponse{Error: &apiError{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+func TestAPIAnalyzeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	analyzerInstance = mockAnalyzer
+
+	tests := []struct {
+		name               string
+		method             string
+		body               string
+		mockResult         *analyzer.Result
+		expectedStatusCode int
+		expectedErrorCode  string
+	}{
+		{
+			name:   "Analysis successful",
+			method: http.MethodPost,
+			body:   `{"url": "http://example.com"}`,
+			mockResult: &analyzer.Result{
+				HTMLVersion:   "HTML 5",
+				Title:         "Example Title",
+				HeadingsCount: map[string]int{"h1": 1},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedErrorCode:  "",
+		},
+		{
+			name:   "Upstream status error",
+			method: http.MethodPost,
+			body:   `{"url": "http://example.com"}`,
+			mockResult: &analyzer.Result{
+				HeadingsCount: map[string]int{},
+				ErrorCode:     analyzer.ErrCodeUpstreamStatus,
+				ErrorMessage:  "The server returned a status code of 404.",
+				Stages:        []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedErrorCode:  analyzer.ErrCodeUpstreamStatus,
+		},
+		{
+			name:   "Invalid URL",
+			method: http.MethodPost,
+			body:   `{"url": "example"}`,
+			mockResult: &analyzer.Result{
+				HeadingsCount: map[string]int{},
+				ErrorCode:     analyzer.ErrCodeInvalidURL,
+				ErrorMessage:  "The provided URL is not valid.",
+				Stages:        []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  analyzer.ErrCodeInvalidURL,
+		},
+		{
+			name:   "Fetch timed out",
+			method: http.MethodPost,
+			body:   `{"url": "http://example.com"}`,
+			mockResult: &analyzer.Result{
+				HeadingsCount: map[string]int{},
+				ErrorCode:     analyzer.ErrCodeTimeout,
+				ErrorMessage:  "The analysis timed out.",
+				Stages:        []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+			},
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedErrorCode:  analyzer.ErrCodeTimeout,
+		},
+		{
+			name:   "Link check timed out",
+			method: http.MethodPost,
+			body:   `{"url": "http://example.com"}`,
+			mockResult: &analyzer.Result{
+				Title:         "Example Title",
+				HeadingsCount: map[string]int{"h1": 1},
+				ErrorCode:     analyzer.ErrCodeTimeout,
+				ErrorMessage:  "The analysis timed out.",
+				Stages: []analyzer.StageReport{
+					{Stage: analyzer.PhaseFetch, Status: analyzer.StageOK},
+					{Stage: analyzer.PhaseLinkCheck, Status: analyzer.StagePartial},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedErrorCode:  analyzer.ErrCodeTimeout,
+		},
+		{
+			name:               "Missing URL",
+			method:             http.MethodPost,
+			body:               `{"url": "  "}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Malformed JSON",
+			method:             http.MethodPost,
+			body:               `{"url": `,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Unknown field",
+			method:             http.MethodPost,
+			body:               `{"link": "http://example.com"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Method not allowed",
+			method:             http.MethodGet,
+			body:               "",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedErrorCode:  errCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.mockResult != nil {
+				mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(test.mockResult)
+			}
+
+			req := httptest.NewRequest(test.method, APIVersionPrefix+"/analyze", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			APIAnalyzeHandler(rr, req)
+
+			if rr.Code != test.expectedStatusCode {
+				t.Errorf("Expected status code '%d', got '%d'", test.expectedStatusCode, rr.Code)
+			}
+
+			if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected content type 'application/json', got '%s'", ct)
+			}
+
+			var resp apiResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Expected a JSON response, got error %v", err)
+			}
+
+			if test.expectedErrorCode == "" && resp.Error != nil {
+				t.Errorf("Expected no error, got '%s'", resp.Error.Code)
+			}
+
+			if test.expectedErrorCode != "" && (resp.Error == nil || resp.Error.Code != test.expectedErrorCode) {
+				t.Errorf("Expected error code '%s', got '%v'", test.expectedErrorCode, resp.Error)
+			}
+
+			if test.mockResult != nil && (resp.Result == nil || resp.Result.Title != test.mockResult.Title) {
+				t.Errorf("Expected the analysis result in the response, got '%v'", resp.Result)
+			}
+		})
+	}
+}
+
+func TestAPIAnalyzeHandlerPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	analyzerInstance = mockAnalyzer
+
+	one := 1
+	SetPolicy(&policy.Policy{Rules: []policy.Rule{{Metric: "h1_count", FailAbove: &one, FailBelow: &one}}})
+	defer SetPolicy(nil)
+
+	tests := []struct {
+		name           string
+		headings       map[string]int
+		expectedPassed bool
+	}{
+		{name: "Single h1", headings: map[string]int{"h1": 1}, expectedPassed: true},
+		{name: "Multiple h1", headings: map[string]int{"h1": 2}, expectedPassed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(&analyzer.Result{HeadingsCount: test.headings})
+
+			req := httptest.NewRequest(http.MethodPost, APIVersionPrefix+"/analyze", strings.NewReader(`{"url": "http://example.com"}`))
+			rr := httptest.NewRecorder()
+
+			APIAnalyzeHandler(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected status code '%d', got '%d'", http.StatusOK, rr.Code)
+			}
+
+			var resp apiResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Expected a JSON response, got error %v", err)
+			}
+			if resp.Policy == nil {
+				t.Fatal("Expected the policy report in the response")
+			}
+			if resp.Policy.Passed != test.expectedPassed {
+				t.Errorf("Expected passed '%t', got '%t'", test.expectedPassed, resp.Policy.Passed)
+			}
+		})
+	}
+}
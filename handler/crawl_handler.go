@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// CrawlPath is the path of the site crawl API
+const CrawlPath = APIVersionPrefix + "/crawl"
+
+// errCodeCrawlUnavailable is returned when no crawler is set
+const errCodeCrawlUnavailable = "crawl_unavailable"
+
+var crawlerInstance *crawler.Crawler
+
+// SetCrawler sets the crawler used by the crawl API. Its limits are the maximum limits a
+// request can ask for.
+func SetCrawler(c *crawler.Crawler) {
+	crawlerInstance = c
+}
+
+type crawlRequest struct {
+	URL string `json:"url"`
+	// MaxDepth and MaxPages lower the limits of the crawler for this crawl
+	MaxDepth *int `json:"max_depth"`
+	MaxPages *int `json:"max_pages"`
+}
+
+// APICrawlHandler crawls the site of the URL given in the JSON request body and writes the
+// analysis of each crawled page and the summary of the site as JSON
+func APICrawlHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only POST requests are supported.")
+		return
+	}
+	if crawlerInstance == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errCodeCrawlUnavailable, "Crawling is not enabled.")
+		return
+	}
+
+	var req crawlRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "The 'url' field is required.")
+		return
+	}
+
+	c := *crawlerInstance
+	if req.MaxDepth != nil {
+		if *req.MaxDepth < 0 || *req.MaxDepth > c.MaxDepth {
+			writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, fmt.Sprintf("The 'max_depth' field must be between 0 and %d.", c.MaxDepth))
+			return
+		}
+		c.MaxDepth = *req.MaxDepth
+	}
+	if !lowerMaxPages(w, &c, req.MaxPages) {
+		return
+	}
+
+	res := c.Crawl(r.Context(), req.URL)
+
+	// The crawl fails when its seed cannot be analyzed
+	resp := apiResponse{Crawl: res}
+	status := http.StatusOK
+	switch {
+	case len(res.Pages) == 0:
+		resp.Error = &apiError{Code: analyzer.ErrCodeCanceled, Message: "The crawl was canceled."}
+		status = statusForErrorCode(analyzer.ErrCodeCanceled)
+	case len(res.Pages) == 1 && res.Pages[0].Result.StageFailed():
+		seed := res.Pages[0].Result
+		resp.Error = &apiError{Code: seed.ErrorCode, Message: seed.ErrorMessage}
+		status = statusForErrorCode(seed.ErrorCode)
+	}
+
+	writeJSON(w, status, resp)
+}
+
+// lowerMaxPages lowers the page limit of the crawler to the one requested, if any. When the
+// requested limit is above the limit of the crawler an error response is written and false is
+// returned.
+func lowerMaxPages(w http.ResponseWriter, c *crawler.Crawler, requested *int) bool {
+	if requested == nil {
+		return true
+	}
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = crawler.DefaultMaxPages
+	}
+	if *requested < 1 || *requested > maxPages {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, fmt.Sprintf("The 'max_pages' field must be between 1 and %d.", maxPages))
+		return false
+	}
+	c.MaxPages = *requested
+	return true
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+func TestAPICrawlHandler(t *testing.T) {
+	home := &analyzer.Result{
+		FinalURL:      "https://example.com/",
+		Title:         "Home",
+		InternalLinks: []string{"https://example.com/about"},
+	}
+	about := &analyzer.Result{FinalURL: "https://example.com/about"}
+	notFound := &analyzer.Result{
+		ErrorCode:    analyzer.ErrCodeUpstreamStatus,
+		ErrorMessage: "The server returned a status code of 404.",
+		Stages:       []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+	}
+	timedOut := &analyzer.Result{
+		FinalURL:     "https://example.com/",
+		ErrorCode:    analyzer.ErrCodeTimeout,
+		ErrorMessage: "The analysis timed out.",
+		Stages:       []analyzer.StageReport{{Stage: analyzer.PhaseLinkCheck, Status: analyzer.StagePartial}},
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		body               string
+		mockResults        map[string]*analyzer.Result
+		expectedStatusCode int
+		expectedErrorCode  string
+		expectedPages      int
+	}{
+		{
+			name:   "Crawl successful",
+			method: http.MethodPost,
+			body:   `{"url": "https://example.com/"}`,
+			mockResults: map[string]*analyzer.Result{
+				"https://example.com/":      home,
+				"https://example.com/about": about,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedPages:      2,
+		},
+		{
+			name:   "Lower depth requested",
+			method: http.MethodPost,
+			body:   `{"url": "https://example.com/", "max_depth": 0}`,
+			mockResults: map[string]*analyzer.Result{
+				"https://example.com/": home,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedPages:      1,
+		},
+		{
+			name:   "Seed not found",
+			method: http.MethodPost,
+			body:   `{"url": "https://example.com/"}`,
+			mockResults: map[string]*analyzer.Result{
+				"https://example.com/": notFound,
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedErrorCode:  analyzer.ErrCodeUpstreamStatus,
+			expectedPages:      1,
+		},
+		{
+			name:   "Seed link check timed out",
+			method: http.MethodPost,
+			body:   `{"url": "https://example.com/", "max_depth": 0}`,
+			mockResults: map[string]*analyzer.Result{
+				"https://example.com/": timedOut,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedPages:      1,
+		},
+		{
+			name:               "Depth above the limit",
+			method:             http.MethodPost,
+			body:               `{"url": "https://example.com/", "max_depth": 3}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Page limit above the limit",
+			method:             http.MethodPost,
+			body:               `{"url": "https://example.com/", "max_pages": 11}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Missing URL",
+			method:             http.MethodPost,
+			body:               `{"max_depth": 1}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Method not allowed",
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedErrorCode:  errCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+			for pageURL, res := range test.mockResults {
+				mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), pageURL, nil).Return(res)
+			}
+			SetCrawler(&crawler.Crawler{Analyzer: mockAnalyzer, MaxDepth: 2, MaxPages: 10})
+			defer SetCrawler(nil)
+
+			req := httptest.NewRequest(test.method, CrawlPath, strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			APICrawlHandler(rr, req)
+
+			if rr.Code != test.expectedStatusCode {
+				t.Errorf("Expected status code '%d', got '%d'", test.expectedStatusCode, rr.Code)
+			}
+
+			var resp apiResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Expected a JSON response, got error %v", err)
+			}
+
+			if test.expectedErrorCode == "" && resp.Error != nil {
+				t.Errorf("Expected no error, got '%s'", resp.Error.Code)
+			}
+			if test.expectedErrorCode != "" && (resp.Error == nil || resp.Error.Code != test.expectedErrorCode) {
+				t.Errorf("Expected error code '%s', got '%v'", test.expectedErrorCode, resp.Error)
+			}
+
+			if test.expectedPages > 0 && (resp.Crawl == nil || resp.Crawl.Summary.TotalPages != test.expectedPages) {
+				t.Errorf("Expected a crawl of %d pages in the response, got '%v'", test.expectedPages, resp.Crawl)
+			}
+		})
+	}
+}
+
+func TestAPICrawlHandlerUnavailable(t *testing.T) {
+	SetCrawler(nil)
+
+	req := httptest.NewRequest(http.MethodPost, CrawlPath, strings.NewReader(`{"url": "https://example.com/"}`))
+	rr := httptest.NewRecorder()
+
+	APICrawlHandler(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusServiceUnavailable, rr.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+)
+
+// heartbeatInterval is how often a comment is sent to keep idle event streams open
+var heartbeatInterval = 15 * time.Second
+
+// Server-Sent Event names used by the job event stream
+const (
+	sseEventPhase = "phase"
+	sseEventLink  = "link"
+	sseEventEnd   = "end"
+)
+
+// streamJobEvents streams the progress events of a job as Server-Sent Events. Every event
+// carries its index in the job's event log as the SSE id, so a reconnecting client resumes
+// after the last event it received through the Last-Event-ID header. The stream ends with
+// an "end" event carrying the final state of the job. Phase events do not carry the partial
+// result, which is polled from the job. A client behind the events kept by the job first
+// receives a phase event with the current progress of the job.
+func streamJobEvents(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, analyzer.ErrCodeUnknown, "Streaming is not supported.")
+		return
+	}
+
+	next := 0
+	if lastID, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		next = lastID + 1
+	}
+
+	events, first, job, changed, err := jobManager.Events(id, next)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		if first > next {
+			writeSSE(w, "", sseEventPhase, analyzer.Event{Phase: job.Phase, Checked: job.LinksChecked, Total: job.LinksTotal})
+			next = first
+		}
+		for _, ev := range events {
+			name := sseEventPhase
+			if ev.Link != nil {
+				name = sseEventLink
+			}
+			writeSSE(w, strconv.Itoa(next), name, ev)
+			next++
+		}
+
+		if job.Finished() {
+			writeSSE(w, "", sseEventEnd, job)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-changed:
+		}
+
+		events, first, job, changed, err = jobManager.Events(id, next)
+		if err != nil {
+			// The job expired while streaming
+			writeSSE(w, "", sseEventEnd, jobs.Job{ID: id})
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, id, event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// readSSEEvents reads the event names of a Server-Sent Events stream until it ends
+func readSSEEvents(t *testing.T, url, lastEventID string) []string {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected content type 'text/event-stream', got '%s'", ct)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	return events
+}
+
+func TestStreamJobEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			res := &analyzer.Result{Title: "Example Title"}
+			progress(analyzer.Event{Phase: analyzer.PhaseFetch, Result: res})
+			<-release
+			progress(analyzer.Event{Phase: analyzer.PhaseLinkCheck, Result: res, Total: 1})
+			progress(analyzer.Event{
+				Phase:   analyzer.PhaseLinkCheck,
+				Link:    &analyzer.LinkReport{LinkCheck: utils.LinkCheck{URL: "http://external.com", Accessible: true}},
+				Checked: 1,
+				Total:   1,
+			})
+			progress(analyzer.Event{Phase: analyzer.PhaseDone, Result: res, Checked: 1, Total: 1})
+			return res
+		})
+
+	m := jobs.NewManager(mockAnalyzer, jobs.Config{Workers: 1})
+	defer m.Close()
+	SetJobManager(m)
+	defer SetJobManager(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(JobsHandler))
+	defer server.Close()
+
+	job, err := m.Submit("http://example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Let the analysis finish once the stream is open
+	go func() {
+		for {
+			if j, _ := m.Get(job.ID); j.Phase == analyzer.PhaseFetch {
+				close(release)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	events := readSSEEvents(t, server.URL+JobsPath+"/"+job.ID+"/events", "")
+	expected := []string{sseEventPhase, sseEventPhase, sseEventLink, sseEventPhase, sseEventEnd}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+
+	// Resuming after the link event replays only the remaining events
+	events = readSSEEvents(t, server.URL+JobsPath+"/"+job.ID+"/events", "2")
+	expected = []string{sseEventPhase, sseEventEnd}
+	if strings.Join(events, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestStreamJobEventsNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := jobs.NewManager(analyzerMocks.NewMockPageAnalyzer(ctrl), jobs.Config{Workers: 1})
+	defer m.Close()
+	SetJobManager(m)
+	defer SetJobManager(nil)
+
+	req := httptest.NewRequest(http.MethodGet, JobsPath+"/unknown/events", nil)
+	rr := httptest.NewRecorder()
+
+	JobsHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusNotFound, rr.Code)
+	}
+}
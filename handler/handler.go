@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
 	"github.com/isurukdniss/webpage-analyzer/utils"
 )
 
@@ -11,6 +12,11 @@ var utilsInstance utils.UtilProvider = &utils.Utils{}
 var analyzerInstance analyzer.PageAnalyzer = &analyzer.Analyzer{}
 var templatePath = "web/index.html"
 
+// SetAnalyzer sets the page analyzer used by the web page and the API
+func SetAnalyzer(a analyzer.PageAnalyzer) {
+	analyzerInstance = a
+}
+
 // IndexHandler renders the landing page of the web application
 func IndexHandler(w http.ResponseWriter, r *http.Request) {
 	err := utilsInstance.RenderTemplate(w, r, templatePath, nil)
@@ -24,7 +30,7 @@ func AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		// Execute the analyze logic
 		formURL := r.FormValue("url")
-		res := analyzerInstance.Analyze(formURL)
+		res := analyzerInstance.AnalyzeContext(r.Context(), formURL, nil)
 
 		// Render the output
 		err := utilsInstance.RenderTemplate(w, r, templatePath, res)
@@ -33,4 +39,34 @@ func AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+
+	// Render the result of an asynchronous analysis job
+	if jobID := r.URL.Query().Get("job"); r.Method == http.MethodGet && jobID != "" {
+		err := utilsInstance.RenderTemplate(w, r, templatePath, jobResult(jobID))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+}
+
+// jobResult returns the result of the given job to render it in the web page
+func jobResult(id string) *analyzer.Result {
+	if jobManager == nil {
+		return &analyzer.Result{ErrorMessage: "Asynchronous analysis is not enabled."}
+	}
+
+	job, err := jobManager.Get(id)
+	if err != nil {
+		return &analyzer.Result{ErrorMessage: "The analysis could not be found or its result has expired. Please try again."}
+	}
+
+	switch {
+	case job.Status == jobs.StatusCanceled:
+		return &analyzer.Result{ErrorMessage: "The analysis was canceled."}
+	case !job.Finished() || job.Result == nil:
+		return &analyzer.Result{ErrorMessage: "The analysis is still in progress. Please refresh the page in a moment."}
+	}
+
+	return job.Result
 }
@@ -5,12 +5,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	gomock "go.uber.org/mock/gomock"
 
 	"github.com/isurukdniss/webpage-analyzer/analyzer"
 	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+	"github.com/isurukdniss/webpage-analyzer/utils"
 	"github.com/isurukdniss/webpage-analyzer/utils/mocks"
 )
 
@@ -93,7 +97,7 @@ func TestAnalyzeHandler(t *testing.T) {
 		ExternalLinks:      []string{"http://external.com"},
 	}
 
-	mockAnalyzer.EXPECT().Analyze(formURL).Return(expectedResult)
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), formURL, gomock.Any()).Return(expectedResult)
 	mockUtils.EXPECT().RenderTemplate(gomock.Any(), gomock.Any(), gomock.Any(), expectedResult).Return(nil)
 
 	form := url.Values{"url": {formURL}}
@@ -107,3 +111,136 @@ func TestAnalyzeHandler(t *testing.T) {
 		t.Errorf("Expected status code '%d', got '%d'", http.StatusOK, rr.Code)
 	}
 }
+
+func TestAnalyzeHandlerJobResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	mockUtils := mocks.NewMockUtilProvider(ctrl)
+	utilsInstance = mockUtils
+
+	expectedResult := &analyzer.Result{Title: "Example Title"}
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(expectedResult)
+
+	m := jobs.NewManager(mockAnalyzer, jobs.Config{Workers: 1})
+	SetJobManager(m)
+	defer SetJobManager(nil)
+
+	defer m.Close()
+
+	job, _ := m.Submit("http://example.com")
+	deadline := time.Now().Add(2 * time.Second)
+	for !job.Finished() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		job, _ = m.Get(job.ID)
+	}
+
+	tests := []struct {
+		name           string
+		jobID          string
+		expectedResult func(res *analyzer.Result) bool
+	}{
+		{
+			name:  "Finished job",
+			jobID: job.ID,
+			expectedResult: func(res *analyzer.Result) bool {
+				return res == expectedResult
+			},
+		},
+		{
+			name:  "Unknown job",
+			jobID: "unknown",
+			expectedResult: func(res *analyzer.Result) bool {
+				return res.ErrorMessage != ""
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUtils.EXPECT().RenderTemplate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(w http.ResponseWriter, r *http.Request, templatePath string, data any) error {
+					if res, ok := data.(*analyzer.Result); !ok || !test.expectedResult(res) {
+						t.Errorf("Unexpected template data %v", data)
+					}
+					return nil
+				})
+
+			req := httptest.NewRequest(http.MethodGet, "/analyze?job="+test.jobID, nil)
+			rr := httptest.NewRecorder()
+
+			AnalyzeHandler(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected status code '%d', got '%d'", http.StatusOK, rr.Code)
+			}
+		})
+	}
+}
+
+func TestResultTemplate(t *testing.T) {
+	stages := func(statuses ...string) []analyzer.StageReport {
+		phases := []analyzer.Phase{analyzer.PhaseFetch, analyzer.PhaseParse, analyzer.PhaseLinkDiscovery, analyzer.PhaseChecks, analyzer.PhaseLinkCheck}
+		reports := make([]analyzer.StageReport, len(statuses))
+		for i, status := range statuses {
+			reports[i] = analyzer.StageReport{Stage: phases[i], Status: status}
+		}
+		return reports
+	}
+
+	tests := []struct {
+		name       string
+		res        *analyzer.Result
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name: "Interrupted link check",
+			res: &analyzer.Result{
+				Title:        "Home",
+				ErrorMessage: "The analysis timed out.",
+				Stages:       stages(analyzer.StageOK, analyzer.StageOK, analyzer.StageOK, analyzer.StageOK, analyzer.StagePartial),
+			},
+			expected:   []string{"Analysis Results", "Home", "The analysis timed out.", "link_check: partial"},
+			unexpected: []string{"fetch: ok"},
+		},
+		{
+			name: "Failed fetch",
+			res: &analyzer.Result{
+				ErrorMessage: "The page could not be fetched.",
+				Stages:       stages(analyzer.StageFailed, analyzer.StageSkipped, analyzer.StageSkipped, analyzer.StageSkipped, analyzer.StageSkipped),
+			},
+			expected:   []string{"The page could not be fetched.", "fetch: failed", "link_check: skipped"},
+			unexpected: []string{"Analysis Results"},
+		},
+		{
+			name:       "Error without an analysis",
+			res:        &analyzer.Result{ErrorMessage: "Asynchronous analysis is not enabled."},
+			expected:   []string{"Asynchronous analysis is not enabled."},
+			unexpected: []string{"Analysis Results"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			err := (&utils.Utils{}).RenderTemplate(rr, httptest.NewRequest(http.MethodGet, "/analyze", nil), "../"+templatePath, test.res)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			out := rr.Body.String()
+			for _, expected := range test.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("Expected %q in the page, got\n%s", expected, out)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(out, unexpected) {
+					t.Errorf("Expected no %q in the page, got\n%s", unexpected, out)
+				}
+			}
+		})
+	}
+}
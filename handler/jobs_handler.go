@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+)
+
+// JobsPath is the path of the asynchronous analysis jobs API
+const JobsPath = APIVersionPrefix + "/jobs"
+
+// Error codes returned by the jobs API
+const (
+	errCodeJobsUnavailable = "jobs_unavailable"
+	errCodeQueueFull       = "queue_full"
+	errCodeJobNotFound     = "job_not_found"
+	errCodeJobFinished     = "job_finished"
+)
+
+var jobManager *jobs.Manager
+
+// SetJobManager sets the job manager used by the jobs API
+func SetJobManager(m *jobs.Manager) {
+	jobManager = m
+}
+
+// JobsHandler serves the jobs API. A job is submitted with POST on JobsPath, polled with
+// GET on JobsPath/{id}, canceled with DELETE on JobsPath/{id} and its progress is streamed
+// as Server-Sent Events with GET on JobsPath/{id}/events.
+func JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if jobManager == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errCodeJobsUnavailable, "Asynchronous analysis is not enabled.")
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, JobsPath), "/")
+	if jobID, ok := strings.CutSuffix(id, "/events"); ok {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only GET requests are supported.")
+			return
+		}
+		streamJobEvents(w, r, jobID)
+		return
+	}
+
+	if id == "" {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only POST requests are supported.")
+			return
+		}
+		submitJob(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		job, err := jobManager.Get(id)
+		if err != nil {
+			writeJobError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, apiResponse{Job: &job})
+	case http.MethodDelete:
+		job, err := jobManager.Cancel(id)
+		if err != nil {
+			writeJobError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, apiResponse{Job: &job})
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodDelete)
+		writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only GET and DELETE requests are supported.")
+	}
+}
+
+func submitJob(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAnalyzeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := jobManager.Submit(req.URL)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", JobsPath+"/"+job.ID)
+	writeJSON(w, http.StatusAccepted, apiResponse{Job: &job})
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		writeAPIError(w, http.StatusNotFound, errCodeJobNotFound, "The job does not exist or its result has expired.")
+	case errors.Is(err, jobs.ErrFinished):
+		writeAPIError(w, http.StatusConflict, errCodeJobFinished, "The job has already finished.")
+	case errors.Is(err, jobs.ErrQueueFull):
+		w.Header().Set("Retry-After", "5")
+		writeAPIError(w, http.StatusServiceUnavailable, errCodeQueueFull, "Too many analyses are pending. Please try again later.")
+	case errors.Is(err, jobs.ErrClosed):
+		writeAPIError(w, http.StatusServiceUnavailable, errCodeJobsUnavailable, "The server is shutting down.")
+	default:
+		writeAPIError(w, http.StatusInternalServerError, analyzer.ErrCodeUnknown, "An unexpected error occurred. Please try again.")
+	}
+}
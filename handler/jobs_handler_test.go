@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+)
+
+func serveJobs(t *testing.T, method, path, body string) (int, apiResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	JobsHandler(rr, req)
+
+	var resp apiResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Expected a JSON response, got error %v", err)
+	}
+	return rr.Code, resp
+}
+
+func TestJobsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	expectedResult := &analyzer.Result{Title: "Example Title"}
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), "http://example.com", gomock.Any()).Return(expectedResult)
+
+	m := jobs.NewManager(mockAnalyzer, jobs.Config{Workers: 1})
+	defer m.Close()
+	SetJobManager(m)
+	defer SetJobManager(nil)
+
+	code, resp := serveJobs(t, http.MethodPost, JobsPath, `{"url": "http://example.com"}`)
+	if code != http.StatusAccepted {
+		t.Fatalf("Expected status code '%d', got '%d'", http.StatusAccepted, code)
+	}
+	if resp.Job == nil || resp.Job.ID == "" {
+		t.Fatalf("Expected the submitted job in the response, got %v", resp)
+	}
+	id := resp.Job.ID
+
+	deadline := time.Now().Add(2 * time.Second)
+	for resp.Job.Status != jobs.StatusSucceeded && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		code, resp = serveJobs(t, http.MethodGet, JobsPath+"/"+id, "")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status code '%d', got '%d'", http.StatusOK, code)
+		}
+	}
+	if resp.Job.Result == nil || resp.Job.Result.Title != expectedResult.Title {
+		t.Errorf("Expected the analysis result, got %v", resp.Job.Result)
+	}
+
+	code, resp = serveJobs(t, http.MethodDelete, JobsPath+"/"+id, "")
+	if code != http.StatusConflict || resp.Error.Code != errCodeJobFinished {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusConflict, code)
+	}
+
+	code, resp = serveJobs(t, http.MethodGet, JobsPath+"/unknown", "")
+	if code != http.StatusNotFound || resp.Error.Code != errCodeJobNotFound {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusNotFound, code)
+	}
+
+	code, _ = serveJobs(t, http.MethodPost, JobsPath, `{}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusBadRequest, code)
+	}
+
+	code, _ = serveJobs(t, http.MethodGet, JobsPath, "")
+	if code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusMethodNotAllowed, code)
+	}
+}
+
+func TestJobsHandlerUnavailable(t *testing.T) {
+	SetJobManager(nil)
+
+	code, resp := serveJobs(t, http.MethodPost, JobsPath, `{"url": "http://example.com"}`)
+	if code != http.StatusServiceUnavailable || resp.Error.Code != errCodeJobsUnavailable {
+		t.Errorf("Expected status code '%d', got '%d'", http.StatusServiceUnavailable, code)
+	}
+}
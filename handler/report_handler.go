@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+	"github.com/isurukdniss/webpage-analyzer/report"
+)
+
+// ReportPath is the path the web page downloads the reports of the analysis jobs from
+const ReportPath = "/report"
+
+// ReportHandler writes the report of the analysis job given by the job query parameter as a
+// file in the format given by the format query parameter, eg. csv or html
+func ReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Only GET requests are supported.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writer, ok := report.Lookup(r.URL.Query().Get("format"))
+	if !ok {
+		http.Error(w, "Unknown report format.", http.StatusBadRequest)
+		return
+	}
+	if jobManager == nil {
+		http.Error(w, "Asynchronous analysis is not enabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	job, err := jobManager.Get(r.URL.Query().Get("job"))
+	switch {
+	case err != nil:
+		http.Error(w, "The analysis could not be found or its result has expired.", http.StatusNotFound)
+		return
+	case job.Status == jobs.StatusCanceled:
+		http.Error(w, "The analysis was canceled.", http.StatusConflict)
+		return
+	case !job.Finished() || job.Result == nil:
+		http.Error(w, "The analysis is still in progress.", http.StatusConflict)
+		return
+	}
+
+	a := report.Analysis{URL: job.URL, Result: job.Result}
+	if policyInstance != nil {
+		a.Policy = policyInstance.Evaluate(job.Result)
+	}
+	filename := "webpage-analysis-" + job.ID + "." + writer.Extension()
+	w.Header().Set("Content-Type", writer.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	writer.WriteAnalyses(w, []report.Analysis{a})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/jobs"
+)
+
+func TestReportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(&analyzer.Result{
+		FinalURL:      "http://example.com",
+		Title:         "Example Title",
+		HeadingsCount: map[string]int{"h1": 1},
+	})
+
+	m := jobs.NewManager(mockAnalyzer, jobs.Config{Workers: 1})
+	SetJobManager(m)
+	defer SetJobManager(nil)
+	defer m.Close()
+
+	job, _ := m.Submit("http://example.com")
+	deadline := time.Now().Add(2 * time.Second)
+	for !job.Finished() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		job, _ = m.Get(job.ID)
+	}
+
+	tests := []struct {
+		name                string
+		method              string
+		query               string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "HTML report",
+			method:              http.MethodGet,
+			query:               "?format=html&job=" + job.ID,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<td>Example Title</td>",
+		},
+		{
+			name:                "CSV summary",
+			method:              http.MethodGet,
+			query:               "?format=csv-summary&job=" + job.ID,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "http://example.com,http://example.com,,,,Example Title,",
+		},
+		{
+			name:               "Unknown format",
+			method:             http.MethodGet,
+			query:              "?format=pdf&job=" + job.ID,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Unknown job",
+			method:             http.MethodGet,
+			query:              "?format=csv&job=unknown",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "Method not allowed",
+			method:             http.MethodPost,
+			query:              "?format=csv&job=" + job.ID,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, ReportPath+test.query, nil)
+			rr := httptest.NewRecorder()
+
+			ReportHandler(rr, req)
+
+			if rr.Code != test.expectedStatusCode {
+				t.Errorf("Expected status code '%d', got '%d'", test.expectedStatusCode, rr.Code)
+			}
+			if test.expectedStatusCode != http.StatusOK {
+				return
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != test.expectedContentType {
+				t.Errorf("Expected content type '%s', got '%s'", test.expectedContentType, ct)
+			}
+			if cd := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment; filename=webpage-analysis-"+job.ID) {
+				t.Errorf("Expected the report as an attachment, got '%s'", cd)
+			}
+			if !strings.Contains(rr.Body.String(), test.expectedBody) {
+				t.Errorf("Expected '%s' in the report, got\n%s", test.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// SitemapPath is the path of the sitemap crawl API
+const SitemapPath = APIVersionPrefix + "/sitemap"
+
+type sitemapRequest struct {
+	// Sitemap is the URL of the sitemap to crawl
+	Sitemap string `json:"sitemap"`
+	// URL is the URL of the site whose sitemaps are discovered from its robots.txt
+	URL string `json:"url"`
+	// MaxPages lowers the page limit of the crawler for this crawl
+	MaxPages *int `json:"max_pages"`
+}
+
+// APISitemapHandler analyzes the URLs listed in the sitemap, or in the sitemaps of the site,
+// given in the JSON request body and writes the analysis of each page and the sitemap report as JSON
+func APISitemapHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, errCodeMethodNotAllowed, "Only POST requests are supported.")
+		return
+	}
+	if crawlerInstance == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errCodeCrawlUnavailable, "Crawling is not enabled.")
+		return
+	}
+
+	var req sitemapRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	req.Sitemap = strings.TrimSpace(req.Sitemap)
+	req.URL = strings.TrimSpace(req.URL)
+	if (req.Sitemap == "") == (req.URL == "") {
+		writeAPIError(w, http.StatusBadRequest, errCodeInvalidRequest, "Exactly one of the 'sitemap' and 'url' fields is required.")
+		return
+	}
+
+	c := *crawlerInstance
+	if !lowerMaxPages(w, &c, req.MaxPages) {
+		return
+	}
+
+	var res *crawler.Result
+	var err error
+	if req.Sitemap != "" {
+		res, err = c.CrawlSitemap(r.Context(), req.Sitemap)
+	} else {
+		res, err = c.CrawlSiteSitemaps(r.Context(), req.URL)
+	}
+	if err != nil {
+		code, message := analyzer.DescribeError(err)
+		writeAPIError(w, statusForErrorCode(code), code, message)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{Crawl: res})
+}
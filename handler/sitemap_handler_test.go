@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	analyzerMocks "github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+	utilsMocks "github.com/isurukdniss/webpage-analyzer/utils/mocks"
+)
+
+func TestAPISitemapHandler(t *testing.T) {
+	sitemap := &utils.Sitemap{
+		URLs:  []utils.SitemapURL{{Loc: "https://example.com/"}, {Loc: "https://example.com/about"}},
+		Files: []string{"https://example.com/sitemap.xml"},
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		body               string
+		discovered         []string
+		fetchErr           error
+		expectedAnalyses   int
+		expectedStatusCode int
+		expectedErrorCode  string
+		expectedPages      int
+	}{
+		{
+			name:               "Sitemap crawled",
+			method:             http.MethodPost,
+			body:               `{"sitemap": "https://example.com/sitemap.xml"}`,
+			expectedAnalyses:   2,
+			expectedStatusCode: http.StatusOK,
+			expectedPages:      2,
+		},
+		{
+			name:               "Sitemaps discovered",
+			method:             http.MethodPost,
+			body:               `{"url": "https://example.com/", "max_pages": 1}`,
+			discovered:         []string{"https://example.com/sitemap.xml"},
+			expectedAnalyses:   1,
+			expectedStatusCode: http.StatusOK,
+			expectedPages:      1,
+		},
+		{
+			name:               "Sitemap not found",
+			method:             http.MethodPost,
+			body:               `{"sitemap": "https://example.com/sitemap.xml"}`,
+			fetchErr:           &utils.StatusError{StatusCode: http.StatusNotFound},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedErrorCode:  analyzer.ErrCodeUpstreamStatus,
+		},
+		{
+			name:               "Invalid sitemap",
+			method:             http.MethodPost,
+			body:               `{"sitemap": "https://example.com/sitemap.xml"}`,
+			fetchErr:           fmt.Errorf("%w: unexpected root element <html>", utils.ErrInvalidSitemap),
+			expectedStatusCode: http.StatusBadGateway,
+			expectedErrorCode:  analyzer.ErrCodeInvalidSitemap,
+		},
+		{
+			name:               "Both sitemap and URL",
+			method:             http.MethodPost,
+			body:               `{"sitemap": "https://example.com/sitemap.xml", "url": "https://example.com/"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Missing sitemap and URL",
+			method:             http.MethodPost,
+			body:               `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Page limit above the limit",
+			method:             http.MethodPost,
+			body:               `{"sitemap": "https://example.com/sitemap.xml", "max_pages": 11}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorCode:  errCodeInvalidRequest,
+		},
+		{
+			name:               "Method not allowed",
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedErrorCode:  errCodeMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockUtils := utilsMocks.NewMockUtilProvider(ctrl)
+			crawler.SetUtils(mockUtils)
+			defer crawler.SetUtils(&utils.Utils{})
+			mockAnalyzer := analyzerMocks.NewMockPageAnalyzer(ctrl)
+
+			if test.discovered != nil {
+				mockUtils.EXPECT().DiscoverSitemaps(gomock.Any(), "https://example.com/").Return(test.discovered, nil)
+			}
+			if test.expectedAnalyses > 0 || test.fetchErr != nil {
+				res := sitemap
+				if test.fetchErr != nil {
+					res = nil
+				}
+				mockUtils.EXPECT().FetchSitemap(gomock.Any(), "https://example.com/sitemap.xml").Return(res, test.fetchErr)
+			}
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), nil).
+				Return(&analyzer.Result{StatusCode: http.StatusOK}).Times(test.expectedAnalyses)
+			SetCrawler(&crawler.Crawler{Analyzer: mockAnalyzer, MaxPages: 10})
+			defer SetCrawler(nil)
+
+			req := httptest.NewRequest(test.method, SitemapPath, strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			APISitemapHandler(rr, req)
+
+			if rr.Code != test.expectedStatusCode {
+				t.Errorf("Expected status code '%d', got '%d'", test.expectedStatusCode, rr.Code)
+			}
+
+			var resp apiResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Expected a JSON response, got error %v", err)
+			}
+
+			if test.expectedErrorCode == "" && resp.Error != nil {
+				t.Errorf("Expected no error, got '%s'", resp.Error.Code)
+			}
+			if test.expectedErrorCode != "" && (resp.Error == nil || resp.Error.Code != test.expectedErrorCode) {
+				t.Errorf("Expected error code '%s', got '%v'", test.expectedErrorCode, resp.Error)
+			}
+
+			if test.expectedPages > 0 && (resp.Crawl == nil || resp.Crawl.Sitemap == nil || len(resp.Crawl.Pages) != test.expectedPages) {
+				t.Errorf("Expected a sitemap crawl of %d pages in the response, got '%v'", test.expectedPages, resp.Crawl)
+			}
+		})
+	}
+}
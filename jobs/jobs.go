@@ -0,0 +1,454 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+// Status represents the lifecycle state of a job
+type Status string
+
+// Job statuses. Succeeded, Failed and Canceled are final.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Default settings used for the zero values of Config
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+	DefaultResultTTL = 10 * time.Minute
+)
+
+var (
+	// ErrQueueFull is returned when a job is submitted while the queue is at capacity
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrNotFound is returned for unknown or expired job IDs
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when canceling a job that has already finished
+	ErrFinished = errors.New("job already finished")
+	// ErrClosed is returned when a job is submitted after the manager is closed
+	ErrClosed = errors.New("job manager is closed")
+)
+
+// maxEvents is the number of the latest progress events a job keeps for the event streams
+const maxEvents = 1000
+
+// Config holds the settings of the job manager
+type Config struct {
+	// Workers is the number of jobs analyzed concurrently
+	Workers int
+	// QueueSize is the maximum number of jobs waiting for a worker
+	QueueSize int
+	// ResultTTL is how long finished jobs are kept before they expire
+	ResultTTL time.Duration
+}
+
+// Job is a point-in-time snapshot of an analysis job
+type Job struct {
+	ID     string           `json:"id"`
+	URL    string           `json:"url"`
+	Status Status           `json:"status"`
+	Phase  analyzer.Phase   `json:"phase,omitempty"`
+	Result *analyzer.Result `json:"result,omitempty"`
+	// LinksChecked and LinksTotal report the progress of the link checks
+	LinksChecked int        `json:"links_checked"`
+	LinksTotal   int        `json:"links_total"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job is in a final state
+func (j Job) Finished() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// job is the mutable state of a job owned by the manager
+type job struct {
+	mu   sync.Mutex
+	snap Job
+	// events is the log of the latest progress events received from the analyzer, without
+	// their result which is kept once in snap. dropped counts the events discarded before them.
+	events  []analyzer.Event
+	dropped int
+	// changed is closed and replaced whenever the job changes, to wake up the subscribers
+	changed chan struct{}
+	// cancel aborts the analysis of a running job
+	cancel context.CancelFunc
+}
+
+func newJob(id, pageURL string) *job {
+	return &job{
+		snap: Job{
+			ID:        id,
+			URL:       pageURL,
+			Status:    StatusQueued,
+			CreatedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+func (j *job) snapshot() Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snap
+}
+
+// notify wakes up the subscribers waiting for a change. j.mu must be held.
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Manager runs analysis jobs on a fixed pool of workers fed by a bounded queue
+type Manager struct {
+	analyzer analyzer.PageAnalyzer
+	cfg      Config
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	closed bool
+	// queue holds the jobs waiting for a worker, the canceled jobs are removed from it
+	queue []*job
+
+	// wake is signaled when a job is queued, to wake up an idle worker
+	wake chan struct{}
+	// ctx is the parent context of the running analyses, it is canceled on Close
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a job manager and starts its workers. Close must be called to stop them.
+func NewManager(a analyzer.PageAnalyzer, cfg Config) *Manager {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.ResultTTL <= 0 {
+		cfg.ResultTTL = DefaultResultTTL
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	m := &Manager{
+		analyzer: a,
+		cfg:      cfg,
+		jobs:     make(map[string]*job),
+		wake:     make(chan struct{}, cfg.QueueSize),
+		ctx:      ctx,
+		stop:     stop,
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+
+	m.wg.Add(1)
+	go m.janitor()
+
+	return m
+}
+
+// Submit queues the analysis of the given URL and returns the queued job
+func (m *Manager) Submit(pageURL string) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	j := newJob(id, pageURL)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return Job{}, ErrClosed
+	}
+
+	if len(m.queue) >= m.cfg.QueueSize {
+		return Job{}, ErrQueueFull
+	}
+	m.queue = append(m.queue, j)
+	m.jobs[id] = j
+
+	// A full channel already wakes up the workers, which drain the queue before idling
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return j.snapshot(), nil
+}
+
+// Get returns the current state of the job with the given ID
+func (m *Manager) Get(id string) (Job, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return Job{}, err
+	}
+	return j.snapshot(), nil
+}
+
+// Cancel cancels a queued or running job. The analysis of a running job is aborted and
+// the job keeps its partial result.
+func (m *Manager) Cancel(id string) (Job, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return Job{}, err
+	}
+	// A queued job leaves the queue so that it no longer counts toward QueueSize
+	m.unqueue(j)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.snap.Finished() {
+		return j.snap, ErrFinished
+	}
+
+	now := time.Now()
+	j.snap.Status = StatusCanceled
+	j.snap.FinishedAt = &now
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.notify()
+
+	return j.snap, nil
+}
+
+// Events returns the progress events of the job starting at index from, the index of the
+// first returned event, the current state of the job and a channel that is closed on the next
+// change of the job. Only the latest events are kept: when the events from index from were
+// discarded, the events start at a later index and the state of the job holds the progress.
+func (m *Manager) Events(id string, from int) ([]analyzer.Event, int, Job, <-chan struct{}, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return nil, 0, Job{}, nil, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	first := max(from, j.dropped)
+	var events []analyzer.Event
+	if i := first - j.dropped; i < len(j.events) {
+		events = append(events, j.events[i:]...)
+	}
+
+	return events, first, j.snap, j.changed, nil
+}
+
+// Close stops the workers, aborts the running analyses, cancels the jobs still waiting
+// in the queue and waits for the workers to return
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.stop()
+	m.mu.Unlock()
+
+	m.wg.Wait()
+
+	m.mu.Lock()
+	queued := m.queue
+	m.queue = nil
+	m.mu.Unlock()
+
+	for _, j := range queued {
+		m.Cancel(j.snapshot().ID)
+	}
+}
+
+// next removes the first job of the queue and returns it, or nil if the queue is empty
+func (m *Manager) next() *job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.queue) == 0 {
+		return nil
+	}
+	j := m.queue[0]
+	m.queue[0] = nil
+	m.queue = m.queue[1:]
+	return j
+}
+
+// unqueue removes the job from the queue if it is still waiting for a worker
+func (m *Manager) unqueue(j *job) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, queued := range m.queue {
+		if queued == j {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *Manager) lookup(id string) (*job, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+
+	if !ok || m.expired(j, time.Now()) {
+		return nil, ErrNotFound
+	}
+	return j, nil
+}
+
+func (m *Manager) expired(j *job, now time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snap.FinishedAt != nil && now.Sub(*j.snap.FinishedAt) > m.cfg.ResultTTL
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for {
+		// Prefer stopping over picking up another job once the manager is closed
+		select {
+		case <-m.ctx.Done():
+			return
+		default:
+		}
+
+		if j := m.next(); j != nil {
+			m.run(j)
+			continue
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.wake:
+		}
+	}
+}
+
+func (m *Manager) run(j *job) {
+	j.mu.Lock()
+	if j.snap.Status != StatusQueued {
+		// Canceled while waiting in the queue
+		j.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+
+	now := time.Now()
+	j.snap.Status = StatusRunning
+	j.snap.StartedAt = &now
+	j.cancel = cancel
+	pageURL := j.snap.URL
+	j.mu.Unlock()
+
+	res := m.analyzer.AnalyzeContext(ctx, pageURL, func(ev analyzer.Event) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if j.snap.Status != StatusRunning {
+			return
+		}
+
+		j.snap.Phase = ev.Phase
+		j.snap.LinksChecked = ev.Checked
+		j.snap.LinksTotal = ev.Total
+		if ev.Result != nil {
+			j.snap.Result = ev.Result
+			ev.Result = nil
+		}
+		if len(j.events) == maxEvents {
+			j.events = j.events[1:]
+			j.dropped++
+		}
+		j.events = append(j.events, ev)
+		j.notify()
+	})
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.snap.Status != StatusRunning {
+		// Canceled while running, keep the partial result
+		return
+	}
+
+	finished := time.Now()
+	switch {
+	case ctx.Err() != nil:
+		// Aborted because the manager was closed
+		j.snap.Status = StatusCanceled
+	case res.StageFailed():
+		j.snap.Status = StatusFailed
+	default:
+		// An interrupted link check does not fail the job, its partial result is kept
+		j.snap.Status = StatusSucceeded
+	}
+	j.snap.Phase = analyzer.PhaseDone
+	j.snap.Result = res
+	j.snap.FinishedAt = &finished
+	j.notify()
+}
+
+// janitor periodically removes the finished jobs whose results have expired
+func (m *Manager) janitor() {
+	defer m.wg.Done()
+
+	interval := m.cfg.ResultTTL
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.removeExpired(now)
+		}
+	}
+}
+
+func (m *Manager) removeExpired(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, j := range m.jobs {
+		if m.expired(j, now) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
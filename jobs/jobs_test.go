@@ -0,0 +1,329 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/analyzer/mocks"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// waitForStatus polls the job until it reaches the expected status
+func waitForStatus(t *testing.T, m *Manager, id string, expected Status) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Expected job '%s' to exist, got error %v", id, err)
+		}
+		if job.Status == expected {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected job '%s' to reach status '%s'", id, expected)
+	return Job{}
+}
+
+func TestSubmit(t *testing.T) {
+	tests := []struct {
+		name           string
+		result         *analyzer.Result
+		expectedStatus Status
+	}{
+		{
+			name:           "Analysis succeeded",
+			result:         &analyzer.Result{Title: "Test Page"},
+			expectedStatus: StatusSucceeded,
+		},
+		{
+			name: "Analysis failed",
+			result: &analyzer.Result{
+				ErrorCode: analyzer.ErrCodeFetchFailed,
+				Stages:    []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+			},
+			expectedStatus: StatusFailed,
+		},
+		{
+			name: "Link check timed out",
+			result: &analyzer.Result{
+				Title:     "Test Page",
+				ErrorCode: analyzer.ErrCodeTimeout,
+				Stages:    []analyzer.StageReport{{Stage: analyzer.PhaseLinkCheck, Status: analyzer.StagePartial}},
+			},
+			expectedStatus: StatusSucceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+			m := NewManager(mockAnalyzer, Config{Workers: 1})
+			defer m.Close()
+
+			mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), "http://example.com", gomock.Any()).Return(test.result)
+
+			job, err := m.Submit("http://example.com")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if job.ID == "" {
+				t.Error("Expected a job ID")
+			}
+
+			job = waitForStatus(t, m, job.ID, test.expectedStatus)
+
+			if job.Result != test.result {
+				t.Errorf("Expected result %v, got %v", test.result, job.Result)
+			}
+			if job.Phase != analyzer.PhaseDone {
+				t.Errorf("Expected phase '%s', got '%s'", analyzer.PhaseDone, job.Phase)
+			}
+			if job.StartedAt == nil || job.FinishedAt == nil {
+				t.Error("Expected the start and finish times to be set")
+			}
+		})
+	}
+}
+
+func TestPartialResultAndCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1})
+	defer m.Close()
+
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	partial := &analyzer.Result{Title: "Partial"}
+
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			progress(analyzer.Event{Phase: analyzer.PhaseLinkCheck, Result: partial})
+			close(started)
+			<-ctx.Done()
+			close(aborted)
+			return &analyzer.Result{Title: "Final"}
+		})
+
+	job, err := m.Submit("http://example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	<-started
+
+	running := waitForStatus(t, m, job.ID, StatusRunning)
+	if running.Phase != analyzer.PhaseLinkCheck || running.Result != partial {
+		t.Errorf("Expected the partial result of phase '%s', got '%s' %v", analyzer.PhaseLinkCheck, running.Phase, running.Result)
+	}
+
+	canceled, err := m.Cancel(job.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if canceled.Status != StatusCanceled {
+		t.Errorf("Expected status '%s', got '%s'", StatusCanceled, canceled.Status)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the running analysis to be aborted")
+	}
+
+	if _, err := m.Cancel(job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected error %v, got %v", ErrFinished, err)
+	}
+
+	// Wait for the worker to return, the final result must not replace the partial one
+	m.Close()
+	final, _ := m.Get(job.ID)
+	if final.Status != StatusCanceled || final.Result != partial {
+		t.Errorf("Expected the canceled job to keep the partial result, got '%s' %v", final.Status, final.Result)
+	}
+}
+
+func TestQueueFullAndClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			close(started)
+			<-ctx.Done()
+			return &analyzer.Result{ErrorCode: analyzer.ErrCodeCanceled}
+		})
+
+	running, _ := m.Submit("http://example.com/1")
+	<-started
+
+	queued, err := m.Submit("http://example.com/2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := m.Submit("http://example.com/3"); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected error %v, got %v", ErrQueueFull, err)
+	}
+
+	// Closing the manager aborts the running analysis
+	m.Close()
+
+	if job, _ := m.Get(running.ID); job.Status != StatusCanceled {
+		t.Errorf("Expected the running job to be canceled on close, got '%s'", job.Status)
+	}
+	if job, _ := m.Get(queued.ID); job.Status != StatusCanceled {
+		t.Errorf("Expected the queued job to be canceled on close, got '%s'", job.Status)
+	}
+	if _, err := m.Submit("http://example.com/4"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1, QueueSize: 1})
+	defer m.Close()
+
+	started := make(chan struct{})
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), "http://example.com/1", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			close(started)
+			<-ctx.Done()
+			return &analyzer.Result{ErrorCode: analyzer.ErrCodeCanceled}
+		})
+
+	m.Submit("http://example.com/1")
+	<-started
+
+	queued, _ := m.Submit("http://example.com/2")
+	if _, err := m.Cancel(queued.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The canceled job no longer takes the place of a queued job
+	if _, err := m.Submit("http://example.com/3"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1, ResultTTL: time.Minute})
+	defer m.Close()
+
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(&analyzer.Result{})
+
+	job, _ := m.Submit("http://example.com")
+	waitForStatus(t, m, job.ID, StatusSucceeded)
+
+	m.removeExpired(time.Now())
+	if _, err := m.Get(job.ID); err != nil {
+		t.Errorf("Expected the job to be kept before the TTL, got %v", err)
+	}
+
+	m.removeExpired(time.Now().Add(2 * time.Minute))
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1})
+	defer m.Close()
+
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			res := &analyzer.Result{}
+			progress(analyzer.Event{Phase: analyzer.PhaseFetch, Result: res})
+			progress(analyzer.Event{Phase: analyzer.PhaseLinkCheck, Link: &analyzer.LinkReport{LinkCheck: utils.LinkCheck{URL: "http://test.com"}}, Checked: 1, Total: 2})
+			progress(analyzer.Event{Phase: analyzer.PhaseDone, Result: res, Checked: 2, Total: 2})
+			return res
+		})
+
+	job, _ := m.Submit("http://example.com")
+
+	_, _, _, changed, err := m.Events(job.ID, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected to be notified of the job progress")
+	}
+
+	job = waitForStatus(t, m, job.ID, StatusSucceeded)
+	if job.LinksChecked != 2 || job.LinksTotal != 2 {
+		t.Errorf("Expected 2 of 2 links checked, got %d of %d", job.LinksChecked, job.LinksTotal)
+	}
+
+	events, first, _, _, _ := m.Events(job.ID, 0)
+	if len(events) != 3 || first != 0 {
+		t.Fatalf("Expected 3 events from 0, got %d from %d", len(events), first)
+	}
+	if events[0].Result != nil {
+		t.Error("Expected the result to be kept by the job only")
+	}
+
+	events, first, _, _, _ = m.Events(job.ID, 1)
+	if len(events) != 2 || first != 1 || events[0].Link == nil {
+		t.Errorf("Expected the events from the link event onwards, got %v from %d", events, first)
+	}
+
+	if _, _, _, _, err := m.Events("unknown", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestEventsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockAnalyzer := mocks.NewMockPageAnalyzer(ctrl)
+
+	m := NewManager(mockAnalyzer, Config{Workers: 1})
+	defer m.Close()
+
+	total := maxEvents + 10
+	mockAnalyzer.EXPECT().AnalyzeContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pageURL string, progress analyzer.ProgressFunc) *analyzer.Result {
+			for i := 1; i <= total; i++ {
+				progress(analyzer.Event{Phase: analyzer.PhaseLinkCheck, Link: &analyzer.LinkReport{}, Checked: i, Total: total})
+			}
+			return &analyzer.Result{}
+		})
+
+	job, _ := m.Submit("http://example.com")
+	waitForStatus(t, m, job.ID, StatusSucceeded)
+
+	// The discarded events are skipped
+	events, first, _, _, _ := m.Events(job.ID, 0)
+	if len(events) != maxEvents || first != 10 || events[0].Checked != 11 {
+		t.Fatalf("Expected the latest %d events from 10, got %d from %d", maxEvents, len(events), first)
+	}
+
+	events, first, _, _, _ = m.Events(job.ID, total-1)
+	if len(events) != 1 || first != total-1 || events[0].Checked != total {
+		t.Errorf("Expected the last event, got %d from %d", len(events), first)
+	}
+}
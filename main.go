@@ -1,22 +1,18 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/isurukdniss/webpage-analyzer/handler"
+	"github.com/isurukdniss/webpage-analyzer/cli"
 )
 
-var stylesPathPattern = "/styles/"
-var stylesDir = "web/styles"
-
 func main() {
-	fs := http.FileServer(http.Dir(stylesDir))
-	http.Handle(stylesPathPattern, http.StripPrefix(stylesPathPattern, fs))
-
-	http.HandleFunc("/", handler.IndexHandler)
-	http.HandleFunc("/analyze", handler.AnalyzeHandler)
-
-	log.Println("Server running at :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	// Canceled on SIGINT or SIGTERM, which aborts the in-flight analyses
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], &cli.Env{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr})
+	stop()
+	os.Exit(code)
 }
@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+// metric measures an aspect of the result of an analysis
+type metric struct {
+	// stage is the stage of the analysis the metric is measured by
+	stage analyzer.Phase
+	value func(res *analyzer.Result) int
+}
+
+// metrics are the metrics the rules can check, by name
+var metrics = map[string]metric{
+	"inaccessible_links":          {analyzer.PhaseLinkCheck, func(res *analyzer.Result) int { return res.InAccessibleLinks }},
+	"inaccessible_internal_links": {analyzer.PhaseLinkCheck, func(res *analyzer.Result) int { return res.InAccessibleInternalLinks }},
+	"inaccessible_external_links": {analyzer.PhaseLinkCheck, func(res *analyzer.Result) int { return res.InAccessibleExternalLinks }},
+	"internal_links":              {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return res.InternalLinksCount }},
+	"external_links":              {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return res.ExternalLinksCount }},
+	"link_warnings":               {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return len(res.LinkWarnings) }},
+	"missing_title":               {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return boolMetric(strings.TrimSpace(res.Title) == "") }},
+	"title_length":                {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return utf8.RuneCountInString(strings.TrimSpace(res.Title)) }},
+	"h1_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h1")},
+	"h2_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h2")},
+	"h3_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h3")},
+	"h4_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h4")},
+	"h5_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h5")},
+	"h6_count":                    {analyzer.PhaseLinkDiscovery, headingMetric("h6")},
+	"login_form":                  {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return boolMetric(res.HasLoginForm) }},
+	"noindex":                     {analyzer.PhaseLinkDiscovery, func(res *analyzer.Result) int { return boolMetric(res.Noindex) }},
+	"redirects":                   {analyzer.PhaseFetch, func(res *analyzer.Result) int { return len(res.Redirects) }},
+	"decoded_bytes":               {analyzer.PhaseParse, func(res *analyzer.Result) int { return int(res.DecodedBytes) }},
+}
+
+// Metrics returns the names of the metrics the rules can check, sorted
+func Metrics() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func headingMetric(level string) func(res *analyzer.Result) int {
+	return func(res *analyzer.Result) int {
+		return res.HeadingsCount[level]
+	}
+}
+
+// boolMetric is 1 for true and 0 for false
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
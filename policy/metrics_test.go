@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+func TestMetrics(t *testing.T) {
+	res := &analyzer.Result{
+		Title:                     "  Home  ",
+		HeadingsCount:             map[string]int{"h1": 2, "h3": 1},
+		InternalLinksCount:        4,
+		ExternalLinksCount:        3,
+		InAccessibleLinks:         2,
+		InAccessibleInternalLinks: 1,
+		InAccessibleExternalLinks: 1,
+		LinkWarnings:              []analyzer.LinkWarning{{}},
+		HasLoginForm:              true,
+		Redirects:                 []utils.Redirect{{}, {}},
+	}
+
+	tests := []struct {
+		metric   string
+		expected int
+	}{
+		{metric: "inaccessible_links", expected: 2},
+		{metric: "inaccessible_internal_links", expected: 1},
+		{metric: "internal_links", expected: 4},
+		{metric: "external_links", expected: 3},
+		{metric: "link_warnings", expected: 1},
+		{metric: "missing_title", expected: 0},
+		{metric: "title_length", expected: 4},
+		{metric: "h1_count", expected: 2},
+		{metric: "h2_count", expected: 0},
+		{metric: "h3_count", expected: 1},
+		{metric: "login_form", expected: 1},
+		{metric: "noindex", expected: 0},
+		{metric: "redirects", expected: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.metric, func(t *testing.T) {
+			if val := metrics[test.metric].value(res); val != test.expected {
+				t.Errorf("Expected %s %d, got %d", test.metric, test.expected, val)
+			}
+		})
+	}
+
+	if val := metrics["missing_title"].value(&analyzer.Result{Title: " "}); val != 1 {
+		t.Errorf("Expected missing_title 1 for a blank title, got %d", val)
+	}
+}
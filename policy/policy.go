@@ -0,0 +1,181 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+// Statuses of the rules and of the reports
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// analysisRule is the name of the result reported when the analysis itself failed
+const analysisRule = "analysis"
+
+// Policy is a set of rules the result of an analysis is checked against, eg. to fail a
+// deployment when a page regresses
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule checks a metric of the result against thresholds. The rule fails when the metric is
+// above FailAbove or below FailBelow, otherwise it warns when the metric is above WarnAbove or
+// below WarnBelow. Nil thresholds are not checked.
+type Rule struct {
+	// Name identifies the rule in the report. Empty means the metric name.
+	Name      string `json:"name,omitempty"`
+	Metric    string `json:"metric"`
+	WarnAbove *int   `json:"warn_above,omitempty"`
+	WarnBelow *int   `json:"warn_below,omitempty"`
+	FailAbove *int   `json:"fail_above,omitempty"`
+	FailBelow *int   `json:"fail_below,omitempty"`
+}
+
+// Report is the outcome of the evaluation of a policy
+type Report struct {
+	// Status is StatusFail if a rule failed, StatusWarn if a rule warned and StatusPass otherwise
+	Status string `json:"status"`
+	// Passed is false when Status is StatusFail
+	Passed bool         `json:"passed"`
+	Pass   int          `json:"pass"`
+	Warn   int          `json:"warn"`
+	Fail   int          `json:"fail"`
+	Rules  []RuleResult `json:"rules"`
+}
+
+// RuleResult is the outcome of a rule
+type RuleResult struct {
+	Rule    string `json:"rule"`
+	Metric  string `json:"metric,omitempty"`
+	Value   int    `json:"value"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Load reads the JSON policy file
+func Load(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the policy file: %w", err)
+	}
+	defer f.Close()
+
+	p, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse reads a JSON policy and validates its rules
+func Parse(r io.Reader) (*Policy, error) {
+	var p Policy
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("no rules")
+	}
+
+	var errs []error
+	for i, rule := range p.Rules {
+		if _, ok := metrics[rule.Metric]; !ok {
+			errs = append(errs, fmt.Errorf("rule %d: unknown metric %q, expected one of %s", i+1, rule.Metric, strings.Join(Metrics(), ", ")))
+			continue
+		}
+		if rule.WarnAbove == nil && rule.WarnBelow == nil && rule.FailAbove == nil && rule.FailBelow == nil {
+			errs = append(errs, fmt.Errorf("rule %d: no threshold for %s", i+1, rule.Metric))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Evaluate checks the result of an analysis against the rules. When the page could not be
+// fetched or parsed the rules are not checked and the report fails. The rules on the metrics of
+// an interrupted stage, eg. the link check, fail because the metrics are unknown.
+func (p *Policy) Evaluate(res *analyzer.Result) *Report {
+	report := &Report{Rules: make([]RuleResult, 0, len(p.Rules))}
+
+	if res.StageFailed() {
+		report.add(RuleResult{Rule: analysisRule, Status: StatusFail, Message: res.ErrorMessage})
+		return report
+	}
+	for _, rule := range p.Rules {
+		report.add(rule.evaluate(res))
+	}
+	return report
+}
+
+func (r *Report) add(result RuleResult) {
+	r.Rules = append(r.Rules, result)
+	switch result.Status {
+	case StatusFail:
+		r.Fail++
+	case StatusWarn:
+		r.Warn++
+	default:
+		r.Pass++
+	}
+
+	switch {
+	case r.Fail > 0:
+		r.Status = StatusFail
+	case r.Warn > 0:
+		r.Status = StatusWarn
+	default:
+		r.Status = StatusPass
+	}
+	r.Passed = r.Fail == 0
+}
+
+func (rule Rule) evaluate(res *analyzer.Result) RuleResult {
+	name := rule.Name
+	if name == "" {
+		name = rule.Metric
+	}
+	m := metrics[rule.Metric]
+	if status := res.StageStatus(m.stage); status != "" && status != analyzer.StageOK {
+		msg := fmt.Sprintf("%s is unknown, the %s stage is %s", rule.Metric, m.stage, status)
+		return RuleResult{Rule: name, Metric: rule.Metric, Status: StatusFail, Message: msg}
+	}
+
+	value := m.value(res)
+	result := RuleResult{Rule: name, Metric: rule.Metric, Value: value, Status: StatusPass}
+
+	if msg := outOfBounds(rule.Metric, value, rule.FailAbove, rule.FailBelow, "failure"); msg != "" {
+		result.Status, result.Message = StatusFail, msg
+	} else if msg := outOfBounds(rule.Metric, value, rule.WarnAbove, rule.WarnBelow, "warning"); msg != "" {
+		result.Status, result.Message = StatusWarn, msg
+	}
+	return result
+}
+
+// outOfBounds returns the message of a value above or below the thresholds, or an empty
+// string if the value is within them
+func outOfBounds(metric string, value int, above, below *int, level string) string {
+	switch {
+	case above != nil && value > *above:
+		return fmt.Sprintf("%s is %d, above the %s threshold of %d", metric, value, level, *above)
+	case below != nil && value < *below:
+		return fmt.Sprintf("%s is %d, below the %s threshold of %d", metric, value, level, *below)
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedRules []Rule
+		hasError      bool
+	}{
+		{
+			name: "Valid policy",
+			data: `{"rules": [{"name": "Single h1", "metric": "h1_count", "fail_below": 1, "fail_above": 1}, {"metric": "inaccessible_links", "warn_above": 0, "fail_above": 5}]}`,
+			expectedRules: []Rule{
+				{Name: "Single h1", Metric: "h1_count", FailBelow: intPtr(1), FailAbove: intPtr(1)},
+				{Metric: "inaccessible_links", WarnAbove: intPtr(0), FailAbove: intPtr(5)},
+			},
+		},
+		{
+			name:     "Unknown metric",
+			data:     `{"rules": [{"metric": "broken_links", "fail_above": 0}]}`,
+			hasError: true,
+		},
+		{
+			name:     "Rule without a threshold",
+			data:     `{"rules": [{"metric": "h1_count"}]}`,
+			hasError: true,
+		},
+		{
+			name:     "Unknown field",
+			data:     `{"rules": [{"metric": "h1_count", "fail_over": 1}]}`,
+			hasError: true,
+		},
+		{
+			name:     "No rules",
+			data:     `{"rules": []}`,
+			hasError: true,
+		},
+		{
+			name:     "Malformed JSON",
+			data:     `{"rules": `,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := Parse(strings.NewReader(test.data))
+			if test.hasError {
+				if err == nil {
+					t.Error("Expected an error but no error returned")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(p.Rules, test.expectedRules) {
+				t.Errorf("Expected rules %+v, got %+v", test.expectedRules, p.Rules)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"rules": [{"metric": "missing_title", "fail_above": 0}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(p.Rules) != 1 || p.Rules[0].Metric != "missing_title" {
+		t.Errorf("Expected the missing_title rule, got %+v", p.Rules)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file but no error returned")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := &Policy{Rules: []Rule{
+		{Metric: "missing_title", FailAbove: intPtr(0)},
+		{Name: "Single h1", Metric: "h1_count", FailBelow: intPtr(1), FailAbove: intPtr(1)},
+		{Metric: "inaccessible_links", WarnAbove: intPtr(0), FailAbove: intPtr(3)},
+	}}
+	page := func(title string, h1, inaccessible int) *analyzer.Result {
+		return &analyzer.Result{
+			Title:             title,
+			HeadingsCount:     map[string]int{"h1": h1},
+			InAccessibleLinks: inaccessible,
+			Stages:            []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageOK}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		res              *analyzer.Result
+		expectedStatus   string
+		expectedStatuses []string
+		expectedMessages []string
+	}{
+		{
+			name:             "Passed",
+			res:              page("Home", 1, 0),
+			expectedStatus:   StatusPass,
+			expectedStatuses: []string{StatusPass, StatusPass, StatusPass},
+			expectedMessages: []string{"", "", ""},
+		},
+		{
+			name:             "Warned",
+			res:              page("Home", 1, 2),
+			expectedStatus:   StatusWarn,
+			expectedStatuses: []string{StatusPass, StatusPass, StatusWarn},
+			expectedMessages: []string{"", "", "inaccessible_links is 2, above the warning threshold of 0"},
+		},
+		{
+			name:             "Failed",
+			res:              page("", 2, 4),
+			expectedStatus:   StatusFail,
+			expectedStatuses: []string{StatusFail, StatusFail, StatusFail},
+			expectedMessages: []string{
+				"missing_title is 1, above the failure threshold of 0",
+				"h1_count is 2, above the failure threshold of 1",
+				"inaccessible_links is 4, above the failure threshold of 3",
+			},
+		},
+		{
+			name:             "No h1",
+			res:              page("Home", 0, 0),
+			expectedStatus:   StatusFail,
+			expectedStatuses: []string{StatusPass, StatusFail, StatusPass},
+			expectedMessages: []string{"", "h1_count is 0, below the failure threshold of 1", ""},
+		},
+		{
+			name: "Interrupted link check",
+			res: &analyzer.Result{
+				Title:         "Home",
+				HeadingsCount: map[string]int{"h1": 1},
+				Stages: []analyzer.StageReport{
+					{Stage: analyzer.PhaseLinkDiscovery, Status: analyzer.StageOK},
+					{Stage: analyzer.PhaseLinkCheck, Status: analyzer.StagePartial},
+				},
+			},
+			expectedStatus:   StatusFail,
+			expectedStatuses: []string{StatusPass, StatusPass, StatusFail},
+			expectedMessages: []string{"", "", "inaccessible_links is unknown, the link_check stage is partial"},
+		},
+		{
+			name: "Failed analysis",
+			res: &analyzer.Result{
+				ErrorCode:    analyzer.ErrCodeUpstreamStatus,
+				ErrorMessage: "The server returned a status code of 404.",
+				Stages:       []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+			},
+			expectedStatus:   StatusFail,
+			expectedStatuses: []string{StatusFail},
+			expectedMessages: []string{"The server returned a status code of 404."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := p.Evaluate(test.res)
+
+			if report.Status != test.expectedStatus {
+				t.Errorf("Expected status '%s', got '%s'", test.expectedStatus, report.Status)
+			}
+			if report.Passed != (test.expectedStatus != StatusFail) {
+				t.Errorf("Expected passed to be %t, got %t", test.expectedStatus != StatusFail, report.Passed)
+			}
+			if report.Pass+report.Warn+report.Fail != len(report.Rules) {
+				t.Errorf("Expected the counts to sum up to %d rules, got %d, %d and %d", len(report.Rules), report.Pass, report.Warn, report.Fail)
+			}
+
+			var statuses, messages []string
+			for _, rule := range report.Rules {
+				statuses = append(statuses, rule.Status)
+				messages = append(messages, rule.Message)
+			}
+			if !reflect.DeepEqual(statuses, test.expectedStatuses) {
+				t.Errorf("Expected rule statuses %v, got %v", test.expectedStatuses, statuses)
+			}
+			if !reflect.DeepEqual(messages, test.expectedMessages) {
+				t.Errorf("Expected rule messages %q, got %q", test.expectedMessages, messages)
+			}
+		})
+	}
+}
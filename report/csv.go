@@ -0,0 +1,195 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+const csvContentType = "text/csv; charset=utf-8"
+
+// csvLinksWriter writes one row per checked link of the analyzed pages
+type csvLinksWriter struct{}
+
+var csvLinksHeader = []string{
+	"page_url", "link_url", "internal", "state", "accessible", "status_code", "method",
+	"error_class", "error", "response_time_ms", "retries", "redirects", "robots_disallowed",
+}
+
+func (csvLinksWriter) ContentType() string {
+	return csvContentType
+}
+
+func (csvLinksWriter) Extension() string {
+	return "csv"
+}
+
+func (csvLinksWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvLinksHeader)
+	for _, a := range analyses {
+		writeLinkRows(cw, a.URL, a.Result)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (csvLinksWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvLinksHeader)
+	for _, page := range res.Pages {
+		writeLinkRows(cw, page.URL, page.Result)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeLinkRows(cw *csv.Writer, pageURL string, res *analyzer.Result) {
+	for _, link := range res.Links {
+		cw.Write([]string{
+			csvText(pageURL),
+			csvText(link.URL),
+			strconv.FormatBool(link.Internal),
+			link.State,
+			strconv.FormatBool(link.Accessible),
+			csvInt(link.StatusCode),
+			link.Method,
+			link.ErrorClass,
+			csvText(link.Error),
+			strconv.FormatInt(link.ResponseTimeMS, 10),
+			strconv.Itoa(link.Retries),
+			strconv.Itoa(len(link.Redirects)),
+			strconv.FormatBool(link.RobotsDisallowed),
+		})
+	}
+}
+
+// csvSummaryWriter writes one row per analyzed page with its counts, the summary sheet of
+// the links written by csvLinksWriter
+type csvSummaryWriter struct{}
+
+var csvSummaryHeader = []string{
+	"url", "final_url", "status_code", "error_code", "error_message", "title", "html_version",
+	"h1", "h2", "h3", "h4", "h5", "h6", "internal_links", "external_links", "inaccessible_links",
+	"inaccessible_internal_links", "inaccessible_external_links", "login_form", "noindex", "policy_status",
+}
+
+func (csvSummaryWriter) ContentType() string {
+	return csvContentType
+}
+
+func (csvSummaryWriter) Extension() string {
+	return "csv"
+}
+
+func (csvSummaryWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvSummaryHeader)
+	for _, a := range analyses {
+		cw.Write(summaryRecord(a.URL, a.Result, a.Policy))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCrawl writes the row of every crawled page, preceded by its depth and referrer
+func (csvSummaryWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"depth", "referrer"}, csvSummaryHeader...))
+	for _, page := range res.Pages {
+		record := summaryRecord(page.URL, page.Result, page.Policy)
+		cw.Write(append([]string{strconv.Itoa(page.Depth), csvText(page.Referrer)}, record...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func summaryRecord(pageURL string, res *analyzer.Result, report *policy.Report) []string {
+	record := []string{
+		csvText(pageURL),
+		csvText(res.FinalURL),
+		csvInt(res.StatusCode),
+		res.ErrorCode,
+		csvText(res.ErrorMessage),
+		csvText(res.Title),
+		res.HTMLVersion,
+	}
+	for _, level := range []string{"h1", "h2", "h3", "h4", "h5", "h6"} {
+		record = append(record, strconv.Itoa(res.HeadingsCount[level]))
+	}
+	policyStatus := ""
+	if report != nil {
+		policyStatus = report.Status
+	}
+	return append(record,
+		strconv.Itoa(res.InternalLinksCount),
+		strconv.Itoa(res.ExternalLinksCount),
+		strconv.Itoa(res.InAccessibleLinks),
+		strconv.Itoa(res.InAccessibleInternalLinks),
+		strconv.Itoa(res.InAccessibleExternalLinks),
+		strconv.FormatBool(res.HasLoginForm),
+		strconv.FormatBool(res.Noindex),
+		policyStatus,
+	)
+}
+
+// csvFindingsWriter writes one row per finding of the analyzed pages, see Findings
+type csvFindingsWriter struct{}
+
+var csvFindingsHeader = []string{"page_url", "check", "severity", "message", "target"}
+
+func (csvFindingsWriter) ContentType() string {
+	return csvContentType
+}
+
+func (csvFindingsWriter) Extension() string {
+	return "csv"
+}
+
+func (csvFindingsWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvFindingsHeader)
+	for _, a := range analyses {
+		for _, f := range Findings(a) {
+			cw.Write(findingRecord(a.URL, f))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (csvFindingsWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvFindingsHeader)
+	for _, f := range crawlFindings(res) {
+		cw.Write(findingRecord(f.Page, f.Finding))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func findingRecord(pageURL string, f analyzer.Finding) []string {
+	return []string{csvText(pageURL), csvText(f.Check), f.Severity, csvText(f.Message), csvText(f.Target)}
+}
+
+// csvInt formats a number, or an empty cell for zero
+func csvInt(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+// csvText escapes the text of a cell taken from a page, eg. its title. A text starting with
+// a formula character is prefixed with a quote so that spreadsheets don't evaluate it.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVLinksWriter(t *testing.T) {
+	var out strings.Builder
+	if err := (csvLinksWriter{}).WriteCrawl(&out, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected a valid CSV, got error %v", err)
+	}
+	expected := [][]string{
+		csvLinksHeader,
+		{"https://example.com/", "https://example.com/a", "true", "ok", "true", "200", "", "", "", "12", "0", "0", "false"},
+		{"https://example.com/", "https://example.com/gone", "true", "broken", "false", "404", "", "", "", "8", "0", "0", "false"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, got %q", expected, records)
+	}
+}
+
+func TestCSVSummaryWriter(t *testing.T) {
+	var out strings.Builder
+	err := csvSummaryWriter{}.WriteAnalyses(&out, []Analysis{{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected a valid CSV, got error %v", err)
+	}
+	expected := [][]string{
+		csvSummaryHeader,
+		{"https://example.com/", "https://example.com/", "200", "", "", "Home | Example", "HTML 5",
+			"1", "2", "0", "0", "0", "0", "2", "0", "1", "0", "0", "false", "false", "fail"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, got %q", expected, records)
+	}
+
+	out.Reset()
+	if err := (csvSummaryWriter{}).WriteCrawl(&out, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "depth,referrer,url,") || !strings.Contains(out.String(), "\n1,https://example.com/,https://example.com/a,") {
+		t.Errorf("Expected the depth and the referrer of the crawled pages, got\n%s", out.String())
+	}
+}
+
+func TestCSVFindingsWriter(t *testing.T) {
+	var out strings.Builder
+	err := csvFindingsWriter{}.WriteAnalyses(&out, []Analysis{{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected a valid CSV, got error %v", err)
+	}
+	expected := [][]string{
+		csvFindingsHeader,
+		{"https://example.com/", "broken-link", "error", "The link to https://example.com/gone is broken (404).", "https://example.com/gone"},
+		{"https://example.com/", "policy/Single h1", "error", "h1_count is 0, below the failure threshold of 1", ""},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, got %q", expected, records)
+	}
+
+	out.Reset()
+	if err := (csvFindingsWriter{}).WriteCrawl(&out, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "\nhttps://example.com/a,h1,warning,The page has no h1 heading.,\n") {
+		t.Errorf("Expected the findings of the crawled pages, got\n%s", out.String())
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "Home", expected: "Home"},
+		{text: "", expected: ""},
+		{text: "=HYPERLINK(\"http://evil.example\")", expected: "'=HYPERLINK(\"http://evil.example\")"},
+		{text: "+1", expected: "'+1"},
+		{text: "@SUM(A1)", expected: "'@SUM(A1)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if text := csvText(test.text); text != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+// IDs of the checks derived from the result of an analysis, besides the checks of the analyzer
+const (
+	CheckAnalysis   = "analysis"
+	CheckBrokenLink = "broken-link"
+	// CheckPolicyPrefix prefixes the name of a rule of the policy in the ID of its check, eg.
+	// "policy/Single h1"
+	CheckPolicyPrefix = "policy/"
+)
+
+// check describes a check the findings of a page are grouped by
+type check struct {
+	ID          string
+	Description string
+	// Severity is the highest severity of the findings of the check
+	Severity string
+}
+
+var (
+	analysisCheck   = check{ID: CheckAnalysis, Description: "The page can be fetched and parsed.", Severity: analyzer.SeverityError}
+	brokenLinkCheck = check{ID: CheckBrokenLink, Description: "The links of the page are accessible.", Severity: analyzer.SeverityError}
+)
+
+// checkResult is the outcome of a check on a page
+type checkResult struct {
+	check    check
+	findings []analyzer.Finding
+	// skipped is the reason the check was not run, empty if it was run
+	skipped string
+}
+
+// failed reports whether the check found an error
+func (r checkResult) failed() bool {
+	for _, f := range r.findings {
+		if f.Severity == analyzer.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Findings returns the issues found on the page by the checks of the analyzer, by the checks
+// derived from the result of its analysis and by the rules of its policy
+func Findings(a Analysis) []analyzer.Finding {
+	var findings []analyzer.Finding
+	for _, r := range checkPage(a) {
+		findings = append(findings, r.findings...)
+	}
+	return findings
+}
+
+// pageFinding is a finding of a crawled page
+type pageFinding struct {
+	Page string
+	analyzer.Finding
+}
+
+// crawlFindings returns the findings of the crawled pages, in the order of the pages
+func crawlFindings(res *crawler.Result) []pageFinding {
+	var findings []pageFinding
+	for _, a := range crawlAnalyses(res) {
+		for _, f := range Findings(a) {
+			findings = append(findings, pageFinding{Page: a.URL, Finding: f})
+		}
+	}
+	return findings
+}
+
+// checkPage returns the outcome of the checks of the page: the analysis, the checks the
+// analyzer ran on the document, the broken links and the rules of its policy. When the analysis
+// failed the other checks are skipped.
+func checkPage(a Analysis) []checkResult {
+	res := a.Result
+	checks := make([]check, 0, len(res.Checks)+2)
+	checks = append(checks, analysisCheck)
+	for _, id := range res.Checks {
+		checks = append(checks, documentCheck(id))
+	}
+	checks = append(checks, brokenLinkCheck)
+	results := make([]checkResult, 0, len(checks))
+
+	skipped := ""
+	switch {
+	case res.ErrorCode == analyzer.ErrCodeRobotsDisallowed:
+		skipped = "The robots.txt of the site disallows the page."
+	case res.StageFailed():
+		skipped = "The page could not be analyzed."
+	}
+	if skipped != "" {
+		for _, c := range checks {
+			r := checkResult{check: c, skipped: skipped}
+			if c.ID == CheckAnalysis && res.ErrorCode != analyzer.ErrCodeRobotsDisallowed {
+				r.skipped = ""
+				r.findings = []analyzer.Finding{{Check: CheckAnalysis, Severity: analyzer.SeverityError, Message: res.ErrorCode + ": " + res.ErrorMessage}}
+			}
+			results = append(results, r)
+		}
+		return results
+	}
+
+	for _, c := range checks {
+		r := checkResult{check: c}
+		switch c.ID {
+		case CheckAnalysis:
+			// The analysis succeeded
+		case CheckBrokenLink:
+			for _, link := range brokenLinks(res) {
+				r.findings = append(r.findings, analyzer.Finding{
+					Check:    CheckBrokenLink,
+					Severity: analyzer.SeverityError,
+					Message:  fmt.Sprintf("The link to %s is %s (%s).", link.URL, link.State, linkStatus(link.StatusCode, link.ErrorClass)),
+					Target:   link.URL,
+				})
+			}
+		default:
+			for _, f := range res.Findings {
+				if f.Check == c.ID {
+					r.findings = append(r.findings, f)
+				}
+			}
+		}
+		results = append(results, r)
+	}
+	if a.Policy != nil {
+		results = append(results, policyResults(a.Policy)...)
+	}
+	return results
+}
+
+// documentCheck describes a check the analyzer ran on the document. The checks that are not
+// registered, eg. in a result of another instance, are described by their ID.
+func documentCheck(id string) check {
+	c, ok := analyzer.LookupCheck(id)
+	if !ok {
+		return check{ID: id, Description: id, Severity: analyzer.SeverityWarning}
+	}
+	return check{ID: id, Description: c.Description(), Severity: c.Severity()}
+}
+
+// policyResults returns a check per rule of the policy report. The failed rules are errors and
+// the rules that warned are warnings.
+func policyResults(report *policy.Report) []checkResult {
+	results := make([]checkResult, 0, len(report.Rules))
+	for _, rule := range report.Rules {
+		r := checkResult{check: policyCheck(rule)}
+		severity := ""
+		switch rule.Status {
+		case policy.StatusFail:
+			severity = analyzer.SeverityError
+		case policy.StatusWarn:
+			severity = analyzer.SeverityWarning
+		}
+		if severity != "" {
+			r.findings = []analyzer.Finding{{Check: r.check.ID, Severity: severity, Message: rule.Message}}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func policyCheck(rule policy.RuleResult) check {
+	return check{
+		ID:          CheckPolicyPrefix + rule.Rule,
+		Description: fmt.Sprintf("The %s of the page is within the thresholds of the policy.", rule.Metric),
+		Severity:    analyzer.SeverityError,
+	}
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+// failedResult returns the result of a page that could not be fetched
+func failedResult() *analyzer.Result {
+	return &analyzer.Result{
+		ErrorCode:    analyzer.ErrCodeUpstreamStatus,
+		ErrorMessage: "The server returned a status code of 404.",
+		Checks:       builtinChecks(),
+		Stages:       []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+	}
+}
+
+func TestFindings(t *testing.T) {
+	issues := homePage()
+	issues.Links = nil
+	issues.Findings = []analyzer.Finding{
+		{Check: analyzer.CheckH1, Severity: analyzer.SeverityWarning, Message: "The page has 2 h1 headings."},
+		{Check: analyzer.CheckLinkWarning, Severity: analyzer.SeverityNote, Message: "The link has an empty href and leads nowhere."},
+		{Check: "img-alt", Severity: analyzer.SeverityWarning, Message: "The image has no alt attribute.", Target: "banner.png"},
+	}
+	issues.Checks = append(builtinChecks(), "img-alt")
+
+	tests := []struct {
+		name     string
+		analysis Analysis
+		expected []analyzer.Finding
+	}{
+		{
+			name:     "Broken link and failed policy",
+			analysis: Analysis{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()},
+			expected: []analyzer.Finding{
+				{Check: CheckBrokenLink, Severity: analyzer.SeverityError, Message: "The link to https://example.com/gone is broken (404).", Target: "https://example.com/gone"},
+				{Check: "policy/Single h1", Severity: analyzer.SeverityError, Message: "h1_count is 0, below the failure threshold of 1"},
+			},
+		},
+		{
+			name:     "Findings of the analyzer",
+			analysis: Analysis{URL: "https://example.com/", Result: issues},
+			expected: issues.Findings,
+		},
+		{
+			name:     "Failed analysis",
+			analysis: Analysis{URL: "https://example.com/missing", Result: failedResult(), Policy: failedPolicy()},
+			expected: []analyzer.Finding{
+				{Check: CheckAnalysis, Severity: analyzer.SeverityError, Message: "upstream_status: The server returned a status code of 404."},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if findings := Findings(test.analysis); !reflect.DeepEqual(findings, test.expected) {
+				t.Errorf("Expected findings %+v, got %+v", test.expected, findings)
+			}
+		})
+	}
+}
+
+func TestCheckPageSkipped(t *testing.T) {
+	disallowed := &analyzer.Result{
+		RobotsDisallowed: true,
+		ErrorCode:        analyzer.ErrCodeRobotsDisallowed,
+		Checks:           builtinChecks(),
+		Stages:           []analyzer.StageReport{{Stage: analyzer.PhaseFetch, Status: analyzer.StageFailed}},
+	}
+
+	for _, r := range checkPage(Analysis{URL: "https://example.com/private", Result: disallowed}) {
+		if r.skipped == "" || len(r.findings) > 0 {
+			t.Errorf("Expected the %s check to be skipped, got %+v", r.check.ID, r)
+		}
+	}
+
+	results := checkPage(Analysis{URL: "https://example.com/missing", Result: failedResult()})
+	if len(results) != len(builtinChecks())+2 || !results[0].failed() {
+		t.Fatalf("Expected the analysis check to fail, got %+v", results)
+	}
+	for _, r := range results[1:] {
+		if r.skipped == "" {
+			t.Errorf("Expected the %s check to be skipped", r.check.ID)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// htmlWriter writes the reports as standalone HTML documents. The styles are inlined and the
+// documents load no other resource, so they can be opened offline or attached to an email.
+type htmlWriter struct{}
+
+func (htmlWriter) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+func (htmlWriter) Extension() string {
+	return "html"
+}
+
+func (htmlWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	return htmlTemplate.ExecuteTemplate(w, "analyses", analyses)
+}
+
+func (htmlWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	return htmlTemplate.ExecuteTemplate(w, "crawl", struct {
+		*crawler.Result
+		SummaryRows    []summaryRow
+		PolicyChecked  bool
+		PolicyFailures []policyFailure
+		Findings       []pageFinding
+	}{res, summaryRows(res), policyChecked(res.Pages), policyFailures(res.Pages), crawlFindings(res)})
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"headingsText": headingsText,
+	"linkStatus":   linkStatus,
+	"pageStatus":   pageStatus,
+	"policyText":   policyText,
+	"brokenLinks":  brokenLinks,
+	"findings":     Findings,
+	"orNone":       orNone,
+	"yesNo":        yesNo,
+}).Parse(htmlTemplateText))
+
+const htmlTemplateText = `
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h2 { margin-top: 2em; word-break: break-all; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+td { word-break: break-all; }
+.pass { color: #1a7f37; }
+.warn, .warning { color: #9a6700; }
+.note { color: #57606a; }
+.fail, .error { color: #cf222e; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "policy"}}
+<h3>Policy</h3>
+<table>
+<tr><th>Status</th><th>Rule</th><th>Value</th><th>Message</th></tr>
+{{range .Rules}}<tr class="{{.Status}}"><td>{{.Status}}</td><td>{{.Rule}}</td><td>{{.Value}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{define "analyses"}}{{template "head" "Webpage Analysis Report"}}
+<h1>Webpage Analysis Report</h1>
+{{range .}}{{$res := .Result}}
+<h2>{{.URL}}</h2>
+<table>
+{{if $res.ErrorCode}}<tr><th>Error</th><td class="error">{{$res.ErrorCode}}: {{$res.ErrorMessage}}</td></tr>{{end}}
+{{if and $res.FinalURL (ne $res.FinalURL .URL)}}<tr><th>Final URL</th><td>{{$res.FinalURL}}</td></tr>{{end}}
+{{if $res.StatusCode}}<tr><th>Status</th><td>{{$res.StatusCode}}</td></tr>{{end}}
+{{if $res.RobotsDisallowed}}<tr><th>Robots</th><td>disallowed by robots.txt</td></tr>{{end}}
+{{if $res.FinalURL}}
+<tr><th>Title</th><td>{{orNone $res.Title}}</td></tr>
+<tr><th>HTML version</th><td>{{$res.HTMLVersion}}</td></tr>
+<tr><th>Headings</th><td>{{orNone (headingsText $res.HeadingsCount)}}</td></tr>
+<tr><th>Internal links</th><td>{{$res.InternalLinksCount}}</td></tr>
+<tr><th>External links</th><td>{{$res.ExternalLinksCount}}</td></tr>
+<tr><th>Inaccessible links</th><td>{{$res.InAccessibleLinks}}</td></tr>
+<tr><th>Login form</th><td>{{yesNo $res.HasLoginForm}}</td></tr>
+{{if $res.Noindex}}<tr><th>Indexing</th><td>noindex</td></tr>{{end}}
+{{end}}
+{{with .Policy}}<tr><th>Policy</th><td class="{{.Status}}">{{policyText .}}</td></tr>{{end}}
+</table>
+{{with findings .}}
+<h3>Findings</h3>
+<table>
+<tr><th>Severity</th><th>Check</th><th>Message</th><th>Target</th></tr>
+{{range .}}<tr class="{{.Severity}}"><td>{{.Severity}}</td><td>{{.Check}}</td><td>{{.Message}}</td><td>{{.Target}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with brokenLinks $res}}
+<h3>Inaccessible Links</h3>
+<table>
+<tr><th>State</th><th>Status</th><th>URL</th></tr>
+{{range .}}<tr><td>{{.State}}</td><td>{{linkStatus .StatusCode .ErrorClass}}</td><td>{{.URL}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Policy}}{{template "policy" .}}{{end}}
+{{end}}
+</body>
+</html>
+{{end}}
+
+{{define "crawl"}}{{template "head" (printf "Crawl of %s" .Seed)}}
+<h1>Crawl of {{.Seed}}</h1>
+<h2>Pages</h2>
+<table>
+<tr><th>Depth</th><th>Status</th><th>URL</th><th>Title</th>{{if .PolicyChecked}}<th>Policy</th>{{end}}</tr>
+{{range .Pages}}<tr><td>{{.Depth}}</td><td>{{pageStatus .Result}}</td><td>{{.URL}}</td><td>{{.Result.Title}}</td>{{if $.PolicyChecked}}<td{{with .Policy}} class="{{.Status}}"{{end}}>{{with .Policy}}{{.Status}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+<h2>Summary</h2>
+<table>
+{{range .SummaryRows}}<tr><th>{{.Name}}</th><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{with .Findings}}
+<h2>Findings</h2>
+<table>
+<tr><th>Page</th><th>Severity</th><th>Check</th><th>Message</th><th>Target</th></tr>
+{{range .}}<tr class="{{.Severity}}"><td>{{.Page}}</td><td>{{.Severity}}</td><td>{{.Check}}</td><td>{{.Message}}</td><td>{{.Target}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Summary.BrokenLinks}}
+<h2>Broken Links</h2>
+<table>
+<tr><th>State</th><th>Status</th><th>URL</th><th>Linked From</th></tr>
+{{range .}}<tr><td>{{.State}}</td><td>{{linkStatus .StatusCode .ErrorClass}}</td><td>{{.URL}}</td><td>{{range $i, $page := .Pages}}{{if $i}}<br>{{end}}{{$page}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .PolicyFailures}}
+<h2>Policy Failures</h2>
+<table>
+<tr><th>Page</th><th>Rule</th><th>Message</th></tr>
+{{range .}}<tr><td>{{.Page}}</td><td>{{.Rule.Rule}}</td><td>{{.Rule.Message}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+{{end}}
+`
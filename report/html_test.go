@@ -0,0 +1,76 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+func TestHTMLWriter(t *testing.T) {
+	script := &analyzer.Result{FinalURL: "https://example.com/x", Title: "<script>alert(1)</script>", HeadingsCount: map[string]int{}}
+
+	var analyses strings.Builder
+	err := htmlWriter{}.WriteAnalyses(&analyses, []Analysis{
+		{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()},
+		{URL: "https://example.com/x", Result: script},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var crawl strings.Builder
+	if err := (htmlWriter{}).WriteCrawl(&crawl, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		output     string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:   "Analyses",
+			output: analyses.String(),
+			expected: []string{
+				"<!DOCTYPE html>",
+				"<style>",
+				"<h2>https://example.com/</h2>",
+				"<tr><th>Title</th><td>Home | Example</td></tr>",
+				"<tr><td>broken</td><td>404</td><td>https://example.com/gone</td></tr>",
+				`<tr class="error"><td>error</td><td>broken-link</td><td>The link to https://example.com/gone is broken (404).</td><td>https://example.com/gone</td></tr>`,
+				`<tr class="fail"><td>fail</td><td>Single h1</td><td>0</td><td>h1_count is 0, below the failure threshold of 1</td></tr>`,
+				"&lt;script&gt;alert(1)&lt;/script&gt;",
+			},
+			// The report is self-contained and the texts of the pages are escaped
+			unexpected: []string{"<link", "<script", "src="},
+		},
+		{
+			name:   "Crawl",
+			output: crawl.String(),
+			expected: []string{
+				"<title>Crawl of https://example.com/</title>",
+				`<tr><td>1</td><td>200</td><td>https://example.com/a</td><td>About</td><td class="fail">fail</td></tr>`,
+				"<tr><th>Pages failing the policy</th><td>1</td></tr>",
+				`<tr class="warning"><td>https://example.com/a</td><td>warning</td><td>h1</td><td>The page has no h1 heading.</td><td></td></tr>`,
+				"<h2>Broken Links</h2>",
+				"<h2>Policy Failures</h2>",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, expected := range test.expected {
+				if !strings.Contains(test.output, expected) {
+					t.Errorf("Expected %q in the output, got\n%s", expected, test.output)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(test.output, unexpected) {
+					t.Errorf("Expected no %q in the output, got\n%s", unexpected, test.output)
+				}
+			}
+		})
+	}
+}
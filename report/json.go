@@ -0,0 +1,34 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// jsonWriter writes the reports as indented JSON. The analyses are written as an array and a
+// crawl as the crawl object of the crawl API.
+type jsonWriter struct{}
+
+func (jsonWriter) ContentType() string {
+	return "application/json"
+}
+
+func (jsonWriter) Extension() string {
+	return "json"
+}
+
+func (jsonWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	return writeJSON(w, analyses)
+}
+
+func (jsonWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	return writeJSON(w, res)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
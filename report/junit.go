@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// junitWriter writes the reports as JUnit XML for the test views of the CI servers. Every page
+// is a test suite with a test case per check. A check fails when it finds an error, the
+// warnings and notes are written to the output of the test case.
+type junitWriter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func (junitWriter) ContentType() string {
+	return "application/xml"
+}
+
+func (junitWriter) Extension() string {
+	return "xml"
+}
+
+func (junitWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	return writeJUnit(w, analyses)
+}
+
+func (junitWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	return writeJUnit(w, crawlAnalyses(res))
+}
+
+func writeJUnit(w io.Writer, analyses []Analysis) error {
+	suites := junitTestSuites{Name: "webpage-analyzer", Suites: []junitTestSuite{}}
+	for _, a := range analyses {
+		suite := junitSuite(a)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuite returns the test suite of a page, with a test case per check
+func junitSuite(a Analysis) junitTestSuite {
+	suite := junitTestSuite{Name: a.URL}
+	for _, r := range checkPage(a) {
+		tc := junitTestCase{Name: r.check.ID, ClassName: a.URL}
+		var lines []string
+		for _, f := range r.findings {
+			lines = append(lines, f.Severity+": "+f.Message)
+		}
+
+		switch {
+		case r.skipped != "":
+			tc.Skipped = &junitSkipped{Message: r.skipped}
+			suite.Skipped++
+		case r.failed():
+			tc.Failure = &junitFailure{
+				Message: failureMessage(r),
+				Type:    r.check.ID,
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		case len(lines) > 0:
+			tc.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+	}
+	return suite
+}
+
+// failureMessage returns the only error of a failed check, or the number of its errors
+func failureMessage(r checkResult) string {
+	var errs []string
+	for _, f := range r.findings {
+		if f.Severity == analyzer.SeverityError {
+			errs = append(errs, f.Message)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Sprintf("%d errors found by the %s check", len(errs), r.check.ID)
+}
+
+// crawlAnalyses returns the analyses of the crawled pages
+func crawlAnalyses(res *crawler.Result) []Analysis {
+	analyses := make([]Analysis, len(res.Pages))
+	for i, page := range res.Pages {
+		analyses[i] = Analysis{URL: page.URL, Result: page.Result, Policy: page.Policy}
+	}
+	return analyses
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+func TestJUnitWriter(t *testing.T) {
+	var out strings.Builder
+	err := junitWriter{}.WriteAnalyses(&out, []Analysis{
+		{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()},
+		{URL: "https://example.com/missing", Result: failedResult()},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("Expected an XML declaration, got\n%s", out.String())
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(out.String()), &suites); err != nil {
+		t.Fatalf("Expected valid XML, got error %v", err)
+	}
+
+	// The home page runs the 6 checks of the result and the 2 rules of the policy, the missing
+	// page fails the analysis check and skips the others
+	if suites.Tests != 14 || suites.Failures != 3 || suites.Skipped != 5 {
+		t.Errorf("Expected 14 tests, 3 failures and 5 skipped, got %d, %d and %d", suites.Tests, suites.Failures, suites.Skipped)
+	}
+	if len(suites.Suites) != 2 || suites.Suites[0].Name != "https://example.com/" {
+		t.Fatalf("Expected a test suite per page, got %+v", suites.Suites)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, tc := range suites.Suites[0].Cases {
+		cases[tc.Name] = tc
+	}
+	if tc := cases[CheckBrokenLink]; tc.Failure == nil || tc.Failure.Message != "The link to https://example.com/gone is broken (404)." {
+		t.Errorf("Expected the broken-link test case to fail with the broken link, got %+v", tc.Failure)
+	}
+	if tc := cases["policy/Single h1"]; tc.Failure == nil || tc.Failure.Type != "policy/Single h1" {
+		t.Errorf("Expected the policy test case to fail, got %+v", tc.Failure)
+	}
+	if tc := cases[analyzer.CheckTitle]; tc.Failure != nil || tc.Skipped != nil {
+		t.Errorf("Expected the title test case to pass, got %+v", tc)
+	}
+	if tc := suites.Suites[1].Cases[1]; tc.Skipped == nil || tc.Skipped.Message != "The page could not be analyzed." {
+		t.Errorf("Expected the checks of the missing page to be skipped, got %+v", tc)
+	}
+}
+
+func TestJUnitWriterWarnings(t *testing.T) {
+	page := homePage()
+	page.Title = ""
+	page.Links = nil
+	page.Findings = []analyzer.Finding{{Check: analyzer.CheckTitle, Severity: analyzer.SeverityWarning, Message: "The page has no title."}}
+
+	var out strings.Builder
+	if err := (junitWriter{}).WriteAnalyses(&out, []Analysis{{URL: "https://example.com/", Result: page}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "<system-out>warning: The page has no title.</system-out>") || strings.Contains(out.String(), "<failure") {
+		t.Errorf("Expected the warning in the output of a passed test case, got\n%s", out.String())
+	}
+}
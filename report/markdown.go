@@ -0,0 +1,198 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+// markdownWriter writes the reports as GitHub flavored Markdown tables
+type markdownWriter struct{}
+
+func (markdownWriter) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+func (markdownWriter) Extension() string {
+	return "md"
+}
+
+// WriteAnalyses writes a section per analysis with the fields of the result, the findings, the
+// inaccessible links and the rules of the policy
+func (markdownWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Webpage Analysis Report")
+	for _, a := range analyses {
+		writeAnalysisMarkdown(bw, a)
+	}
+	return bw.Flush()
+}
+
+func writeAnalysisMarkdown(w io.Writer, a Analysis) {
+	res := a.Result
+	fmt.Fprintf(w, "\n## %s\n\n", mdText(a.URL))
+
+	fmt.Fprintln(w, "| Field | Value |")
+	fmt.Fprintln(w, "|-------|-------|")
+	field := func(name string, val any) {
+		fmt.Fprintf(w, "| %s | %s |\n", name, mdText(fmt.Sprint(val)))
+	}
+	if res.ErrorCode != "" {
+		field("Error", res.ErrorCode+": "+res.ErrorMessage)
+	}
+	if res.FinalURL != "" && res.FinalURL != a.URL {
+		field("Final URL", res.FinalURL)
+	}
+	if res.StatusCode != 0 {
+		field("Status", res.StatusCode)
+	}
+	if res.RobotsDisallowed {
+		field("Robots", "disallowed by robots.txt")
+	}
+	if res.FinalURL != "" {
+		field("Title", orNone(res.Title))
+		field("HTML version", res.HTMLVersion)
+		field("Headings", orNone(headingsText(res.HeadingsCount)))
+		field("Internal links", res.InternalLinksCount)
+		field("External links", res.ExternalLinksCount)
+		field("Inaccessible links", res.InAccessibleLinks)
+		field("Login form", yesNo(res.HasLoginForm))
+		if res.Noindex {
+			field("Indexing", "noindex")
+		}
+	}
+	if a.Policy != nil {
+		field("Policy", policyText(a.Policy))
+	}
+
+	if findings := Findings(a); len(findings) > 0 {
+		fmt.Fprintln(w, "\n### Findings")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Severity | Check | Message | Target |")
+		fmt.Fprintln(w, "|----------|-------|---------|--------|")
+		for _, f := range findings {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", f.Severity, mdText(f.Check), mdText(f.Message), mdText(f.Target))
+		}
+	}
+
+	if broken := brokenLinks(res); len(broken) > 0 {
+		fmt.Fprintln(w, "\n### Inaccessible Links")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| State | Status | URL |")
+		fmt.Fprintln(w, "|-------|--------|-----|")
+		for _, link := range broken {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", link.State, linkStatus(link.StatusCode, link.ErrorClass), mdText(link.URL))
+		}
+	}
+
+	if a.Policy != nil {
+		fmt.Fprintln(w, "\n### Policy")
+		fmt.Fprintln(w)
+		writePolicyMarkdown(w, a.Policy.Rules)
+	}
+}
+
+func writePolicyMarkdown(w io.Writer, rules []policy.RuleResult) {
+	fmt.Fprintln(w, "| Status | Rule | Value | Message |")
+	fmt.Fprintln(w, "|--------|------|-------|---------|")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "| %s | %s | %d | %s |\n", rule.Status, mdText(rule.Rule), rule.Value, mdText(rule.Message))
+	}
+}
+
+// WriteCrawl writes the crawled pages, the summary of the site, the findings of the pages, the
+// broken links and the policy failures
+func (markdownWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Crawl of %s\n", mdText(res.Seed))
+
+	checked := policyChecked(res.Pages)
+	fmt.Fprintln(bw, "\n## Pages")
+	fmt.Fprintln(bw)
+	if checked {
+		fmt.Fprintln(bw, "| Depth | Status | URL | Title | Policy |")
+		fmt.Fprintln(bw, "|-------|--------|-----|-------|--------|")
+	} else {
+		fmt.Fprintln(bw, "| Depth | Status | URL | Title |")
+		fmt.Fprintln(bw, "|-------|--------|-----|-------|")
+	}
+	for _, page := range res.Pages {
+		fmt.Fprintf(bw, "| %d | %s | %s | %s |", page.Depth, pageStatus(page.Result), mdText(page.URL), mdText(page.Result.Title))
+		if checked {
+			status := ""
+			if page.Policy != nil {
+				status = page.Policy.Status
+			}
+			fmt.Fprintf(bw, " %s |", status)
+		}
+		fmt.Fprintln(bw)
+	}
+
+	fmt.Fprintln(bw, "\n## Summary")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "| | Count |")
+	fmt.Fprintln(bw, "|-|-------|")
+	for _, row := range summaryRows(res) {
+		fmt.Fprintf(bw, "| %s | %d |\n", row.Name, row.Count)
+	}
+
+	if findings := crawlFindings(res); len(findings) > 0 {
+		fmt.Fprintln(bw, "\n## Findings")
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, "| Page | Severity | Check | Message | Target |")
+		fmt.Fprintln(bw, "|------|----------|-------|---------|--------|")
+		for _, f := range findings {
+			fmt.Fprintf(bw, "| %s | %s | %s | %s | %s |\n", mdText(f.Page), f.Severity, mdText(f.Check), mdText(f.Message), mdText(f.Target))
+		}
+	}
+
+	if links := res.Summary.BrokenLinks; len(links) > 0 {
+		fmt.Fprintln(bw, "\n## Broken Links")
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, "| State | Status | URL | Linked From |")
+		fmt.Fprintln(bw, "|-------|--------|-----|-------------|")
+		for _, link := range links {
+			pages := make([]string, len(link.Pages))
+			for i, page := range link.Pages {
+				pages[i] = mdText(page)
+			}
+			fmt.Fprintf(bw, "| %s | %s | %s | %s |\n", link.State, linkStatus(link.StatusCode, link.ErrorClass), mdText(link.URL), strings.Join(pages, "<br>"))
+		}
+	}
+
+	if failures := policyFailures(res.Pages); len(failures) > 0 {
+		fmt.Fprintln(bw, "\n## Policy Failures")
+		fmt.Fprintln(bw)
+		fmt.Fprintln(bw, "| Page | Rule | Message |")
+		fmt.Fprintln(bw, "|------|------|---------|")
+		for _, failure := range failures {
+			fmt.Fprintf(bw, "| %s | %s | %s |\n", mdText(failure.Page), mdText(failure.Rule.Rule), mdText(failure.Rule.Message))
+		}
+	}
+	return bw.Flush()
+}
+
+// mdEscaper escapes the characters of a text that Markdown would interpret in a table cell
+var mdEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"|", "\\|",
+	"`", "\\`",
+	"*", "\\*",
+	"_", "\\_",
+	"[", "\\[",
+	"]", "\\]",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// mdText escapes a text taken from a page, eg. its title, for a Markdown table cell
+func mdText(s string) string {
+	return mdEscaper.Replace(s)
+}
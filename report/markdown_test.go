@@ -0,0 +1,81 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownWriter(t *testing.T) {
+	var analyses strings.Builder
+	err := markdownWriter{}.WriteAnalyses(&analyses, []Analysis{{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var crawl strings.Builder
+	if err := (markdownWriter{}).WriteCrawl(&crawl, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name:   "Analyses",
+			output: analyses.String(),
+			expected: []string{
+				"# Webpage Analysis Report\n\n## https://example.com/\n",
+				"| Title | Home \\| Example |\n",
+				"| Policy | fail (1 failed, 0 warned, 1 passed) |\n",
+				"### Findings\n\n| Severity | Check | Message | Target |\n|----------|-------|---------|--------|\n| error | broken-link | The link to https://example.com/gone is broken (404). | https://example.com/gone |\n",
+				"### Inaccessible Links\n\n| State | Status | URL |\n|-------|--------|-----|\n| broken | 404 | https://example.com/gone |\n",
+				"| fail | Single h1 | 0 | h1\\_count is 0, below the failure threshold of 1 |\n",
+			},
+		},
+		{
+			name:   "Crawl",
+			output: crawl.String(),
+			expected: []string{
+				"# Crawl of https://example.com/\n",
+				"| Depth | Status | URL | Title | Policy |\n",
+				"| 1 | 200 | https://example.com/a | About | fail |\n",
+				"| Pages failing the policy | 1 |\n",
+				"| https://example.com/a | warning | h1 | The page has no h1 heading. |  |\n",
+				"| broken | 404 | https://example.com/gone | https://example.com/ |\n",
+				"## Policy Failures\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, expected := range test.expected {
+				if !strings.Contains(test.output, expected) {
+					t.Errorf("Expected %q in the output, got\n%s", expected, test.output)
+				}
+			}
+		})
+	}
+}
+
+func TestMDText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "Home", expected: "Home"},
+		{text: "A | B", expected: "A \\| B"},
+		{text: "<script>alert(1)</script>", expected: "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{text: "[click](http://evil.example)", expected: "\\[click\\](http://evil.example)"},
+		{text: "line\nbreak", expected: "line break"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if text := mdText(test.text); text != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, text)
+			}
+		})
+	}
+}
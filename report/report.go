@@ -0,0 +1,78 @@
+package report
+
+import (
+	"io"
+	"sort"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+// Formats of the built-in writers
+const (
+	FormatText        = "text"
+	FormatJSON        = "json"
+	FormatCSV         = "csv"
+	FormatCSVSummary  = "csv-summary"
+	FormatCSVFindings = "csv-findings"
+	FormatMarkdown    = "markdown"
+	FormatHTML        = "html"
+	FormatJUnit       = "junit"
+	FormatSARIF       = "sarif"
+)
+
+// Analysis is the result of the analysis of a URL
+type Analysis struct {
+	URL    string           `json:"url"`
+	Result *analyzer.Result `json:"result"`
+	// Policy is the check of the result against the policy, nil without a policy
+	Policy *policy.Report `json:"policy,omitempty"`
+}
+
+// Writer writes the results of the analyses and of the crawls as reports of a format
+type Writer interface {
+	// ContentType is the media type of the reports
+	ContentType() string
+	// Extension is the file name extension of the reports, without the dot
+	Extension() string
+	// WriteAnalyses writes the report of the analyses of one or more URLs
+	WriteAnalyses(w io.Writer, analyses []Analysis) error
+	// WriteCrawl writes the report of a crawl, its pages and its summary
+	WriteCrawl(w io.Writer, res *crawler.Result) error
+}
+
+var writers = map[string]Writer{
+	FormatText:        textWriter{},
+	FormatJSON:        jsonWriter{},
+	FormatCSV:         csvLinksWriter{},
+	FormatCSVSummary:  csvSummaryWriter{},
+	FormatCSVFindings: csvFindingsWriter{},
+	FormatMarkdown:    markdownWriter{},
+	FormatHTML:        htmlWriter{},
+	FormatJUnit:       junitWriter{},
+	FormatSARIF:       sarifWriter{},
+}
+
+// Register adds a writer for the format, or replaces the writer of the format. It is not safe
+// to call concurrently with the other functions of the package, so writers are registered at
+// start up.
+func Register(format string, w Writer) {
+	writers[format] = w
+}
+
+// Lookup returns the writer of the format
+func Lookup(format string) (Writer, bool) {
+	w, ok := writers[format]
+	return w, ok
+}
+
+// Formats returns the formats of the registered writers, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+	"github.com/isurukdniss/webpage-analyzer/utils"
+)
+
+// homePage returns the result of a page with an accessible and an inaccessible link
+func homePage() *analyzer.Result {
+	return &analyzer.Result{
+		FinalURL:           "https://example.com/",
+		StatusCode:         200,
+		Title:              "Home | Example",
+		HTMLVersion:        "HTML 5",
+		HeadingsCount:      map[string]int{"h1": 1, "h2": 2},
+		InternalLinksCount: 2,
+		InAccessibleLinks:  1,
+		Checks:             builtinChecks(),
+		Links: []analyzer.LinkReport{
+			{LinkCheck: utils.LinkCheck{URL: "https://example.com/a", Accessible: true, State: utils.LinkStateOK, StatusCode: 200, ResponseTimeMS: 12}, Internal: true},
+			{LinkCheck: utils.LinkCheck{URL: "https://example.com/gone", State: utils.LinkStateBroken, StatusCode: 404, ResponseTimeMS: 8}, Internal: true},
+		},
+	}
+}
+
+// builtinChecks returns the IDs of the checks the analyzer runs by default
+func builtinChecks() []string {
+	return []string{analyzer.CheckTitle, analyzer.CheckH1, analyzer.CheckLinkWarning, analyzer.CheckNoindex}
+}
+
+// failedPolicy returns a report with a failed rule
+func failedPolicy() *policy.Report {
+	return &policy.Report{
+		Status: policy.StatusFail,
+		Fail:   1,
+		Pass:   1,
+		Rules: []policy.RuleResult{
+			{Rule: "missing_title", Metric: "missing_title", Status: policy.StatusPass},
+			{Rule: "Single h1", Metric: "h1_count", Value: 0, Status: policy.StatusFail, Message: "h1_count is 0, below the failure threshold of 1"},
+		},
+	}
+}
+
+// siteCrawl returns a crawl of two pages, the second failing the policy
+func siteCrawl() *crawler.Result {
+	about := &analyzer.Result{
+		FinalURL:      "https://example.com/a",
+		StatusCode:    200,
+		Title:         "About",
+		HeadingsCount: map[string]int{},
+		Checks:        builtinChecks(),
+		Findings:      []analyzer.Finding{{Check: analyzer.CheckH1, Severity: analyzer.SeverityWarning, Message: "The page has no h1 heading."}},
+	}
+	return &crawler.Result{
+		Seed: "https://example.com/",
+		Pages: []crawler.Page{
+			{URL: "https://example.com/", Result: homePage(), Policy: &policy.Report{Status: policy.StatusPass, Passed: true, Pass: 2}},
+			{URL: "https://example.com/a", Depth: 1, Referrer: "https://example.com/", Result: about, Policy: failedPolicy()},
+		},
+		Summary: crawler.Summary{
+			TotalPages: 2,
+			BrokenLinks: []crawler.BrokenLink{
+				{URL: "https://example.com/gone", State: utils.LinkStateBroken, StatusCode: 404, Pages: []string{"https://example.com/"}},
+			},
+			PagesWithoutH1:    []string{"https://example.com/a"},
+			PolicyFailedPages: []string{"https://example.com/a"},
+		},
+	}
+}
+
+// customWriter is a writer registered by a test
+type customWriter struct{}
+
+func (customWriter) ContentType() string                                  { return "text/plain" }
+func (customWriter) Extension() string                                    { return "txt" }
+func (customWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error { return nil }
+func (customWriter) WriteCrawl(w io.Writer, res *crawler.Result) error    { return nil }
+
+func TestFormats(t *testing.T) {
+	expected := []string{FormatCSV, FormatCSVFindings, FormatCSVSummary, FormatHTML, FormatJSON, FormatJUnit, FormatMarkdown, FormatSARIF, FormatText}
+	if formats := Formats(); !reflect.DeepEqual(formats, expected) {
+		t.Errorf("Expected formats %v, got %v", expected, formats)
+	}
+	for _, format := range expected {
+		if _, ok := Lookup(format); !ok {
+			t.Errorf("Expected a writer for the format '%s'", format)
+		}
+	}
+	if _, ok := Lookup("pdf"); ok {
+		t.Error("Expected no writer for the format 'pdf'")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("custom", customWriter{})
+	defer delete(writers, "custom")
+
+	w, ok := Lookup("custom")
+	if !ok {
+		t.Fatal("Expected the registered writer")
+	}
+	if _, ok := w.(customWriter); !ok {
+		t.Errorf("Expected the custom writer, got %T", w)
+	}
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"io"
+
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+)
+
+// SARIF version and schema of the reports
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifWriter writes the reports as SARIF for the code scanning views. The checks are the
+// rules of the run and every finding is a result located at the URL of its page.
+type sarifWriter struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func (sarifWriter) ContentType() string {
+	return "application/sarif+json"
+}
+
+func (sarifWriter) Extension() string {
+	return "sarif"
+}
+
+func (sarifWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	return writeJSON(w, sarifReport(analyses))
+}
+
+func (sarifWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	return writeJSON(w, sarifReport(crawlAnalyses(res)))
+}
+
+// sarifReport returns a single run with the results of all the pages. The rules are the
+// checks in the order they were first run.
+func sarifReport(analyses []Analysis) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "webpage-analyzer",
+			InformationURI: "https://github.com/isurukdniss/webpage-analyzer",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, a := range analyses {
+		for _, r := range checkPage(a) {
+			i, ok := ruleIndex[r.check.ID]
+			if !ok {
+				i = len(run.Tool.Driver.Rules)
+				ruleIndex[r.check.ID] = i
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:                   r.check.ID,
+					ShortDescription:     sarifMessage{Text: r.check.Description},
+					DefaultConfiguration: sarifConfiguration{Level: r.check.Severity},
+				})
+			}
+
+			for _, f := range r.findings {
+				result := sarifResult{
+					RuleID:    r.check.ID,
+					RuleIndex: i,
+					Level:     f.Severity,
+					Message:   sarifMessage{Text: f.Message},
+					Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: a.URL},
+					}}},
+				}
+				if f.Target != "" {
+					result.Properties = map[string]string{"target": f.Target}
+				}
+				run.Results = append(run.Results, result)
+			}
+		}
+	}
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
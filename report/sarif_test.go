@@ -0,0 +1,63 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+)
+
+func TestSARIFWriter(t *testing.T) {
+	var out strings.Builder
+	if err := (sarifWriter{}).WriteCrawl(&out, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out.String()), &log); err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("Expected a single SARIF 2.1.0 run, got version '%s' and %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	expectedRules := []string{CheckAnalysis, analyzer.CheckTitle, analyzer.CheckH1, analyzer.CheckLinkWarning, analyzer.CheckNoindex, CheckBrokenLink, "policy/missing_title", "policy/Single h1"}
+	if !reflect.DeepEqual(ruleIDs, expectedRules) {
+		t.Errorf("Expected rules %v, got %v", expectedRules, ruleIDs)
+	}
+
+	expected := []sarifResult{
+		{
+			RuleID:     CheckBrokenLink,
+			RuleIndex:  5,
+			Level:      analyzer.SeverityError,
+			Message:    sarifMessage{Text: "The link to https://example.com/gone is broken (404)."},
+			Locations:  []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "https://example.com/"}}}},
+			Properties: map[string]string{"target": "https://example.com/gone"},
+		},
+		{
+			RuleID:    analyzer.CheckH1,
+			RuleIndex: 2,
+			Level:     analyzer.SeverityWarning,
+			Message:   sarifMessage{Text: "The page has no h1 heading."},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "https://example.com/a"}}}},
+		},
+		{
+			RuleID:    "policy/Single h1",
+			RuleIndex: 7,
+			Level:     analyzer.SeverityError,
+			Message:   sarifMessage{Text: "h1_count is 0, below the failure threshold of 1"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "https://example.com/a"}}}},
+		},
+	}
+	if !reflect.DeepEqual(run.Results, expected) {
+		t.Errorf("Expected results %+v, got %+v", expected, run.Results)
+	}
+}
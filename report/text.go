@@ -0,0 +1,269 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/isurukdniss/webpage-analyzer/analyzer"
+	"github.com/isurukdniss/webpage-analyzer/crawler"
+	"github.com/isurukdniss/webpage-analyzer/policy"
+)
+
+// textWriter writes the reports as aligned plain text for terminals. The report of several
+// analyses is the concatenation of the reports of each one, so they can be written one by one.
+type textWriter struct{}
+
+func (textWriter) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (textWriter) Extension() string {
+	return "txt"
+}
+
+// WriteAnalyses writes each result as aligned fields, followed by the inaccessible links, the
+// findings of the checks and the rules of the policy that did not pass
+func (textWriter) WriteAnalyses(w io.Writer, analyses []Analysis) error {
+	bw := bufio.NewWriter(w)
+	for _, a := range analyses {
+		writeAnalysisText(bw, a)
+	}
+	return bw.Flush()
+}
+
+func writeAnalysisText(w io.Writer, a Analysis) {
+	res := a.Result
+	fmt.Fprintln(w, a.URL)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	field := func(name string, val any) {
+		fmt.Fprintf(tw, "  %s\t%v\n", name, val)
+	}
+
+	if res.ErrorCode != "" {
+		field("Error", res.ErrorCode+": "+res.ErrorMessage)
+	}
+	if res.FinalURL != "" && res.FinalURL != a.URL {
+		field("Final URL", res.FinalURL)
+	}
+	if res.StatusCode != 0 {
+		field("Status", res.StatusCode)
+	}
+	if res.RobotsDisallowed {
+		field("Robots", "disallowed by robots.txt")
+	}
+	if res.FinalURL != "" {
+		field("Title", orNone(res.Title))
+		field("HTML version", res.HTMLVersion)
+		field("Headings", orNone(headingsText(res.HeadingsCount)))
+		field("Links", fmt.Sprintf("%d internal, %d external, %d inaccessible",
+			res.InternalLinksCount, res.ExternalLinksCount, res.InAccessibleLinks))
+		field("Login form", yesNo(res.HasLoginForm))
+		if res.Noindex {
+			field("Indexing", "noindex")
+		}
+	}
+	if a.Policy != nil {
+		field("Policy", policyText(a.Policy))
+	}
+	tw.Flush()
+
+	if broken := brokenLinks(res); len(broken) > 0 {
+		fmt.Fprintln(w, "  Inaccessible links:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, link := range broken {
+			fmt.Fprintf(tw, "    %s\t%s\t%s\n", link.State, linkStatus(link.StatusCode, link.ErrorClass), link.URL)
+		}
+		tw.Flush()
+	}
+
+	if len(res.Findings) > 0 {
+		fmt.Fprintln(w, "  Findings:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range res.Findings {
+			line := "    " + f.Severity + "\t" + f.Check + "\t" + f.Message
+			if f.Target != "" {
+				line += "\t" + f.Target
+			}
+			fmt.Fprintln(tw, line)
+		}
+		tw.Flush()
+	}
+
+	if a.Policy != nil && a.Policy.Status != policy.StatusPass {
+		fmt.Fprintln(w, "  Policy rules:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, rule := range a.Policy.Rules {
+			if rule.Status != policy.StatusPass {
+				fmt.Fprintf(tw, "    %s\t%s\t%s\n", rule.Status, rule.Rule, rule.Message)
+			}
+		}
+		tw.Flush()
+	}
+	fmt.Fprintln(w)
+}
+
+// WriteCrawl writes the crawled pages as a table, followed by the summary of the site
+func (textWriter) WriteCrawl(w io.Writer, res *crawler.Result) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "Crawl of %s\n\n", res.Seed)
+
+	tw := tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEPTH\tSTATUS\tURL\tTITLE")
+	for _, page := range res.Pages {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", page.Depth, pageStatus(page.Result), page.URL, page.Result.Title)
+	}
+	tw.Flush()
+
+	s := res.Summary
+	fmt.Fprintln(bw, "\nSummary:")
+	tw = tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+	for _, row := range summaryRows(res) {
+		fmt.Fprintf(tw, "  %s\t%d\n", row.Name, row.Count)
+	}
+	tw.Flush()
+
+	if len(s.BrokenLinks) > 0 {
+		fmt.Fprintln(bw, "\nBroken links:")
+		tw = tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+		for _, link := range s.BrokenLinks {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\tlinked from %d page(s)\n", link.State, linkStatus(link.StatusCode, link.ErrorClass), link.URL, len(link.Pages))
+		}
+		tw.Flush()
+	}
+
+	if len(s.PolicyFailedPages) > 0 {
+		fmt.Fprintln(bw, "\nPolicy failures:")
+		tw = tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+		for _, failure := range policyFailures(res.Pages) {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", failure.Page, failure.Rule.Rule, failure.Rule.Message)
+		}
+		tw.Flush()
+	}
+	return bw.Flush()
+}
+
+// summaryRow is a count of the summary of a crawl
+type summaryRow struct {
+	Name  string
+	Count int
+}
+
+// summaryRows returns the counts of the summary of a crawl. The pages failing the policy are
+// only counted when the pages were checked against a policy.
+func summaryRows(res *crawler.Result) []summaryRow {
+	s := res.Summary
+	rows := []summaryRow{
+		{"Pages", s.TotalPages},
+		{"Failed pages", len(s.FailedPages)},
+		{"Disallowed pages", len(s.DisallowedPages)},
+		{"Skipped pages", s.SkippedPages},
+		{"Broken links", len(s.BrokenLinks)},
+		{"Pages missing a title", len(s.PagesMissingTitle)},
+		{"Pages without an h1", len(s.PagesWithoutH1)},
+		{"Pages with a login form", len(s.PagesWithLoginForm)},
+	}
+	if policyChecked(res.Pages) {
+		rows = append(rows, summaryRow{"Pages failing the policy", len(s.PolicyFailedPages)})
+	}
+	return rows
+}
+
+// policyFailure is a rule of the policy failed by a crawled page
+type policyFailure struct {
+	Page string
+	Rule policy.RuleResult
+}
+
+// policyFailures lists the failed rules of the crawled pages in the order of the pages
+func policyFailures(pages []crawler.Page) []policyFailure {
+	var failures []policyFailure
+	for _, page := range pages {
+		if page.Policy == nil || page.Policy.Passed {
+			continue
+		}
+		for _, rule := range page.Policy.Rules {
+			if rule.Status == policy.StatusFail {
+				failures = append(failures, policyFailure{Page: page.URL, Rule: rule})
+			}
+		}
+	}
+	return failures
+}
+
+// policyChecked reports whether the crawled pages were checked against a policy
+func policyChecked(pages []crawler.Page) bool {
+	for _, page := range pages {
+		if page.Policy != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// policyText sums up a policy report, eg. "fail (1 failed, 0 warned, 2 passed)"
+func policyText(report *policy.Report) string {
+	return fmt.Sprintf("%s (%d failed, %d warned, %d passed)", report.Status, report.Fail, report.Warn, report.Pass)
+}
+
+// brokenLinks returns the inaccessible links of the page
+func brokenLinks(res *analyzer.Result) []analyzer.LinkReport {
+	var broken []analyzer.LinkReport
+	for _, link := range res.Links {
+		if !link.Accessible {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
+
+// pageStatus returns the status code of a crawled page, or its error code if no response was
+// received
+func pageStatus(res *analyzer.Result) string {
+	if res.ErrorCode != "" && res.StatusCode == 0 {
+		return res.ErrorCode
+	}
+	return strconv.Itoa(res.StatusCode)
+}
+
+// headingsText lists the heading counts by level, eg. "h1: 1, h2: 3"
+func headingsText(counts map[string]int) string {
+	levels := make([]string, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+
+	parts := make([]string, len(levels))
+	for i, level := range levels {
+		parts[i] = fmt.Sprintf("%s: %d", level, counts[level])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// linkStatus returns the status code of a checked link, or its error class if no response
+// was received
+func linkStatus(statusCode int, errorClass string) string {
+	if statusCode != 0 {
+		return strconv.Itoa(statusCode)
+	}
+	return errorClass
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextWriter(t *testing.T) {
+	var analyses strings.Builder
+	err := textWriter{}.WriteAnalyses(&analyses, []Analysis{
+		{URL: "https://example.com/", Result: homePage(), Policy: failedPolicy()},
+		{URL: "https://example.com/a", Result: siteCrawl().Pages[1].Result},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var crawl strings.Builder
+	if err := (textWriter{}).WriteCrawl(&crawl, siteCrawl()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name:   "Analyses",
+			output: analyses.String(),
+			expected: []string{
+				"https://example.com/\n",
+				"  Title         Home | Example\n",
+				"  Headings      h1: 1, h2: 2\n",
+				"  Policy        fail (1 failed, 0 warned, 1 passed)\n",
+				"    broken  404  https://example.com/gone\n",
+				"    fail  Single h1  h1_count is 0, below the failure threshold of 1\n",
+				"https://example.com/a\n",
+				"  Findings:\n    warning  h1  The page has no h1 heading.\n",
+			},
+		},
+		{
+			name:   "Crawl",
+			output: crawl.String(),
+			expected: []string{
+				"Crawl of https://example.com/\n",
+				"1      200     https://example.com/a  About\n",
+				"  Pages without an h1       1\n",
+				"  Pages failing the policy  1\n",
+				"  broken  404  https://example.com/gone  linked from 1 page(s)\n",
+				"  https://example.com/a  Single h1  h1_count is 0, below the failure threshold of 1\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, expected := range test.expected {
+				if !strings.Contains(test.output, expected) {
+					t.Errorf("Expected %q in the output, got\n%s", expected, test.output)
+				}
+			}
+		})
+	}
+}
+
+func TestHeadingsText(t *testing.T) {
+	tests := []struct {
+		counts   map[string]int
+		expected string
+	}{
+		{counts: map[string]int{}, expected: ""},
+		{counts: map[string]int{"h3": 2, "h1": 1}, expected: "h1: 1, h3: 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			if text := headingsText(test.counts); text != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, text)
+			}
+		})
+	}
+}
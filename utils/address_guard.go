@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// blockedPrefixes are the special purpose networks the requests are not sent to unless allowed:
+// the private, loopback, link-local (including the cloud metadata endpoints), shared, multicast
+// and unspecified addresses. The IPv4 addresses embedded in the NAT64 and 6to4 addresses are
+// checked as well, see embeddedIPv4.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// hostRule matches a host name, a host name suffix, or an IP address range
+type hostRule struct {
+	prefix netip.Prefix
+	host   string
+	// suffix rules match the subdomains of the host
+	suffix bool
+}
+
+// parseHostRules parses host names, "*.domain" wildcards, IP addresses and CIDR ranges
+func parseHostRules(entries []string) ([]hostRule, error) {
+	rules := make([]hostRule, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q", entry)
+			}
+			rules = append(rules, hostRule{prefix: prefix.Masked()})
+		case strings.HasPrefix(entry, "*."):
+			rules = append(rules, hostRule{host: entry[1:], suffix: true})
+		default:
+			if addr, err := netip.ParseAddr(entry); err == nil {
+				rules = append(rules, hostRule{prefix: netip.PrefixFrom(addr, addr.BitLen())})
+			} else {
+				rules = append(rules, hostRule{host: entry})
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (r hostRule) matches(host string, addr netip.Addr) bool {
+	if r.prefix.IsValid() {
+		return addr.IsValid() && r.prefix.Contains(addr)
+	}
+	if r.suffix {
+		return strings.HasSuffix(host, r.host)
+	}
+	return host == r.host
+}
+
+// Networks of the IPv6 addresses embedding an IPv4 address
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour   = netip.MustParsePrefix("2002::/16")
+)
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address is translated to, eg.
+// 169.254.169.254 for 64:ff9b::a9fe:a9fe
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFour.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+// addressGuard keeps the requests from reaching internal services. The address is checked when
+// the connection is made, so that the redirects and the DNS answers changing between the checks
+// cannot get around it.
+type addressGuard struct {
+	allow []hostRule
+	deny  []hostRule
+}
+
+func newAddressGuard(allow []string, deny []string) (*addressGuard, error) {
+	allowRules, err := parseHostRules(allow)
+	if err != nil {
+		return nil, err
+	}
+	denyRules, err := parseHostRules(deny)
+	if err != nil {
+		return nil, err
+	}
+	return &addressGuard{allow: allowRules, deny: denyRules}, nil
+}
+
+// check returns an ErrBlockedAddress error if the host must not be requested at the address.
+// The address is invalid if the host is not resolved yet. The denied hosts are checked first,
+// then the allowed hosts, then the blocked networks.
+func (g *addressGuard) check(host string, addr netip.Addr) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	addr = addr.Unmap()
+
+	for _, rule := range g.deny {
+		if rule.matches(host, addr) {
+			return fmt.Errorf("%w: %s is denied", ErrBlockedAddress, host)
+		}
+	}
+	for _, rule := range g.allow {
+		if rule.matches(host, addr) {
+			return nil
+		}
+	}
+	if addr.IsValid() {
+		translated, ok := embeddedIPv4(addr)
+		for _, prefix := range blockedPrefixes {
+			if prefix.Contains(addr) || (ok && prefix.Contains(translated)) {
+				return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr)
+			}
+		}
+	}
+	return nil
+}
+
+// allowedName reports whether the host name is allowed by name, whatever its addresses
+func (g *addressGuard) allowedName(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, rule := range g.deny {
+		if rule.matches(host, netip.Addr{}) {
+			return false
+		}
+	}
+	for _, rule := range g.allow {
+		if rule.matches(host, netip.Addr{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyAddrKey is the context key of the host:port of the proxy a request is sent through
+type proxyAddrKey struct{}
+
+// dialContext dials with the dialer after checking the address of each connection attempt
+func (g *addressGuard) dialContext(dialer *net.Dialer) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		// The request is sent through the proxy, its host was checked by checkProxied
+		if proxyAddr, _ := ctx.Value(proxyAddrKey{}).(string); proxyAddr == addr {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		guarded := *dialer
+		guarded.ControlContext = func(ctx context.Context, network string, address string, c syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			resolved, err := netip.ParseAddr(ip)
+			if err != nil {
+				return err
+			}
+			return g.check(host, resolved)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+}
+
+// checkProxied returns an ErrBlockedAddress error if the host of a request sent through a proxy
+// must not be requested. The host is resolved to check its addresses, unless it is allowed by
+// name. The proxy resolves the host again, so it is expected to enforce its own egress rules
+// against the DNS answers changing between the two resolutions.
+func (g *addressGuard) checkProxied(req *http.Request) error {
+	host := req.URL.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.check(host, addr)
+	}
+	if err := g.check(host, netip.Addr{}); err != nil || g.allowedName(host) {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(req.Context(), "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := g.check(host, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardedTransport checks the hosts of the requests sent through a proxy, whose connections
+// are made to the proxy rather than to the host. The address of the proxy is set on the context
+// of the request, so that only the connections dialed for the proxied requests skip the check.
+type guardedTransport struct {
+	*http.Transport
+	guard *addressGuard
+	// proxy returns the proxy of a request, nil to send it directly
+	proxy func(*http.Request) (*url.URL, error)
+}
+
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, err := t.proxy(req)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		if err := t.guard.checkProxied(req); err != nil {
+			return nil, err
+		}
+		ctx := context.WithValue(req.Context(), proxyAddrKey{}, canonicalProxyAddr(proxyURL))
+		ctx = context.WithValue(ctx, proxyURLKey{}, proxyURL)
+		req = req.WithContext(ctx)
+	}
+	return t.Transport.RoundTrip(req)
+}
+
+// proxyURLKey is the context key of the proxy a request is sent through
+type proxyURLKey struct{}
+
+// proxyFromContext is the proxy function of the guarded transport, which returns the proxy
+// chosen by RoundTrip
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	proxyURL, _ := req.Context().Value(proxyURLKey{}).(*url.URL)
+	return proxyURL, nil
+}
+
+// canonicalProxyAddr returns the host:port the transport dials to reach the proxy
+func canonicalProxyAddr(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"testing"
+)
+
+// loopback allows the requests to the test servers, which listen on the loopback interface
+var loopback = []string{"127.0.0.0/8", "::1"}
+
+func TestMain(m *testing.M) {
+	// The zero value of Utils blocks the loopback interface like the configured clients
+	defaultTransport, _ = newTransport(ClientConfig{AllowHosts: loopback})
+	os.Exit(m.Run())
+}
+
+func TestAddressGuardCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		allow           []string
+		deny            []string
+		host            string
+		addr            string
+		expectedBlocked bool
+	}{
+		{
+			name: "Public address",
+			host: "example.com",
+			addr: "93.184.215.14",
+		},
+		{
+			name:            "Loopback address",
+			host:            "localhost",
+			addr:            "127.0.0.1",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Private address",
+			host:            "intranet",
+			addr:            "10.1.2.3",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Metadata endpoint",
+			host:            "169.254.169.254",
+			addr:            "169.254.169.254",
+			expectedBlocked: true,
+		},
+		{
+			name:            "IPv4-mapped IPv6 loopback address",
+			host:            "::ffff:127.0.0.1",
+			addr:            "::ffff:127.0.0.1",
+			expectedBlocked: true,
+		},
+		{
+			name:            "IPv6 unique local address",
+			host:            "fd00:ec2::254",
+			addr:            "fd00:ec2::254",
+			expectedBlocked: true,
+		},
+		{
+			name:            "NAT64 metadata endpoint",
+			host:            "64:ff9b::a9fe:a9fe",
+			addr:            "64:ff9b::a9fe:a9fe",
+			expectedBlocked: true,
+		},
+		{
+			name: "NAT64 public address",
+			host: "64:ff9b::5db8:d70e",
+			addr: "64:ff9b::5db8:d70e",
+		},
+		{
+			name:            "6to4 private address",
+			host:            "2002:a00:1::1",
+			addr:            "2002:a00:1::1",
+			expectedBlocked: true,
+		},
+		{
+			name:  "Allowed range",
+			allow: []string{"10.0.0.0/8"},
+			host:  "intranet",
+			addr:  "10.1.2.3",
+		},
+		{
+			name:  "Allowed wildcard host",
+			allow: []string{"*.corp.example"},
+			host:  "wiki.corp.example",
+			addr:  "192.168.1.10",
+		},
+		{
+			name:            "Wildcard does not match the domain itself",
+			allow:           []string{"*.corp.example"},
+			host:            "corp.example",
+			addr:            "192.168.1.10",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Denied host",
+			deny:            []string{"tracker.example.com"},
+			host:            "Tracker.Example.com.",
+			addr:            "93.184.215.14",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Denied address overrides the allowed host",
+			allow:           []string{"wiki.corp.example"},
+			deny:            []string{"192.168.1.10"},
+			host:            "wiki.corp.example",
+			addr:            "192.168.1.10",
+			expectedBlocked: true,
+		},
+		{
+			name: "Host not resolved yet",
+			host: "example.com",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			guard, err := newAddressGuard(test.allow, test.deny)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var addr netip.Addr
+			if test.addr != "" {
+				addr = netip.MustParseAddr(test.addr)
+			}
+			err = guard.check(test.host, addr)
+
+			if blocked := errors.Is(err, ErrBlockedAddress); blocked != test.expectedBlocked {
+				t.Errorf("Expected blocked %v, got %v (%v)", test.expectedBlocked, blocked, err)
+			}
+		})
+	}
+}
+
+func TestNewAddressGuardInvalidRange(t *testing.T) {
+	if _, err := newAddressGuard([]string{"10.0.0.0/33"}, nil); err == nil {
+		t.Error("Expected an error but no error returned")
+	}
+}
+
+func TestFetchURLBlocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			// localhost resolves to the loopback address the page was served from
+			http.Redirect(w, r, "http://localhost:"+serverPort(r)+"/", http.StatusFound)
+			return
+		}
+		w.Write([]byte("Success!"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		cfg             ClientConfig
+		path            string
+		expectedBlocked bool
+	}{
+		{
+			name:            "Loopback blocked by default",
+			path:            "/",
+			expectedBlocked: true,
+		},
+		{
+			name: "Loopback allowed",
+			cfg:  ClientConfig{AllowHosts: loopback},
+			path: "/",
+		},
+		{
+			name:            "Redirect to a denied host",
+			cfg:             ClientConfig{AllowHosts: loopback, DenyHosts: []string{"localhost"}},
+			path:            "/redirect",
+			expectedBlocked: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := New(test.cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			_, err = u.FetchURL(server.URL + test.path)
+			if blocked := errors.Is(err, ErrBlockedAddress); blocked != test.expectedBlocked {
+				t.Errorf("Expected blocked %v, got %v (%v)", test.expectedBlocked, blocked, err)
+			}
+
+			check := u.CheckLink(context.Background(), server.URL+test.path)
+			if blocked := check.State == LinkStateBlocked; blocked != test.expectedBlocked {
+				t.Errorf("Expected the link to be blocked %v, got state '%s'", test.expectedBlocked, check.State)
+			}
+			if check.Accessible == test.expectedBlocked {
+				t.Errorf("Expected the link to be accessible %v, got %v", !test.expectedBlocked, check)
+			}
+		})
+	}
+}
+
+func serverPort(r *http.Request) string {
+	_, port, _ := net.SplitHostPort(r.Host)
+	return port
+}
+
+func TestProxyBlocked(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		name            string
+		cfg             ClientConfig
+		url             string
+		expectedBlocked bool
+	}{
+		{
+			name:            "Host resolving to a blocked address",
+			url:             "http://localhost/",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Blocked address",
+			url:             "http://169.254.169.254/latest/meta-data/",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Address of the proxy",
+			url:             proxy.URL + "/",
+			expectedBlocked: true,
+		},
+		{
+			name:            "Denied host",
+			cfg:             ClientConfig{AllowHosts: []string{"example.test"}, DenyHosts: []string{"example.test"}},
+			url:             "http://example.test/",
+			expectedBlocked: true,
+		},
+		{
+			name: "Allowed host",
+			cfg:  ClientConfig{AllowHosts: []string{"localhost"}},
+			url:  "http://localhost/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proxied = nil
+			test.cfg.Proxy = proxy.URL
+			u, err := New(test.cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			check := u.CheckLink(context.Background(), test.url)
+
+			if blocked := check.State == LinkStateBlocked; blocked != test.expectedBlocked {
+				t.Errorf("Expected the link to be blocked %v, got state '%s'", test.expectedBlocked, check.State)
+			}
+			if sent := len(proxied) > 0; sent == test.expectedBlocked {
+				t.Errorf("Expected the request to be sent to the proxy %v, got %v", !test.expectedBlocked, proxied)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Sources of the character encoding of a page, in the order of precedence
+const (
+	CharsetSourceBOM    = "bom"
+	CharsetSourceHeader = "header"
+	CharsetSourceMeta   = "meta"
+	// CharsetSourceDefault is used when the page declares no known encoding: UTF-8 if the start
+	// of the page is valid UTF-8, windows-1252 otherwise
+	CharsetSourceDefault = "default"
+)
+
+// charsetPrescanSize is the size of the start of the body searched for the <meta> charset
+const charsetPrescanSize = 1024
+
+// Charset is the character encoding of a page
+type Charset struct {
+	// Name is the canonical name of the encoding the page is decoded with
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	// Conflicts lists the declarations of an unknown encoding or of another encoding than the
+	// one the page is decoded with
+	Conflicts []CharsetDeclaration `json:"conflicts,omitempty"`
+}
+
+// CharsetDeclaration is an encoding declared by a source
+type CharsetDeclaration struct {
+	Source string `json:"source"`
+	Label  string `json:"label"`
+}
+
+// decodeBody detects the character encoding of the body and returns the body transcoded to UTF-8
+func decodeBody(body io.ReadCloser, contentType string) (io.ReadCloser, Charset) {
+	buffered := bufio.NewReaderSize(body, charsetPrescanSize)
+	// A read error is returned again by the following reads of the body
+	head, _ := buffered.Peek(charsetPrescanSize)
+
+	cs, enc, bomSize := detectCharset(head, contentType)
+	buffered.Discard(bomSize)
+
+	var decoded io.Reader = buffered
+	if cs.Name != "utf-8" {
+		decoded = transform.NewReader(buffered, enc.NewDecoder())
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{decoded, body}, cs
+}
+
+// detectCharset returns the character encoding of the page from the start of its body and the
+// Content-Type header, and the size of the byte order mark to skip
+func detectCharset(head []byte, contentType string) (Charset, encoding.Encoding, int) {
+	var declarations []CharsetDeclaration
+	bom, label := bomOf(head)
+	if label != "" {
+		declarations = append(declarations, CharsetDeclaration{Source: CharsetSourceBOM, Label: label})
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		declarations = append(declarations, CharsetDeclaration{Source: CharsetSourceHeader, Label: params["charset"]})
+	}
+	if label := metaCharset(head); label != "" {
+		declarations = append(declarations, CharsetDeclaration{Source: CharsetSourceMeta, Label: label})
+	}
+
+	var cs Charset
+	var enc encoding.Encoding
+	for _, d := range declarations {
+		if e, name := charset.Lookup(d.Label); e != nil {
+			cs.Name, cs.Source, enc = name, d.Source, e
+			break
+		}
+	}
+	if enc == nil {
+		label := "windows-1252"
+		if utf8.Valid(trimIncompleteRune(head)) {
+			label = "utf-8"
+		}
+		enc, cs.Name = charset.Lookup(label)
+		cs.Source = CharsetSourceDefault
+	}
+
+	for _, d := range declarations {
+		if _, name := charset.Lookup(d.Label); name != cs.Name {
+			cs.Conflicts = append(cs.Conflicts, d)
+		}
+	}
+
+	if cs.Source != CharsetSourceBOM {
+		bom = nil
+	}
+	return cs, enc, len(bom)
+}
+
+var boms = []struct {
+	bom   []byte
+	label string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// bomOf returns the byte order mark the body starts with and its encoding, if any
+func bomOf(head []byte) ([]byte, string) {
+	for _, b := range boms {
+		if bytes.HasPrefix(head, b.bom) {
+			return b.bom, b.label
+		}
+	}
+	return nil, ""
+}
+
+// metaCharset returns the encoding declared by the first <meta charset> or
+// <meta http-equiv="Content-Type"> element of the start of the body, if any
+func metaCharset(head []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(head))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" {
+				continue
+			}
+
+			var httpEquiv, content string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					return strings.TrimSpace(string(val))
+				case "http-equiv":
+					httpEquiv = string(val)
+				case "content":
+					content = string(val)
+				}
+			}
+
+			if strings.EqualFold(httpEquiv, "content-type") {
+				if _, params, err := mime.ParseMediaType(content); err == nil && params["charset"] != "" {
+					return params["charset"]
+				}
+			}
+		}
+	}
+}
+
+// trimIncompleteRune removes a rune cut at the end of the start of the body
+func trimIncompleteRune(head []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(head); i++ {
+		if utf8.RuneStart(head[len(head)-i]) {
+			if !utf8.FullRune(head[len(head)-i:]) {
+				return head[:len(head)-i]
+			}
+			break
+		}
+	}
+	return head
+}
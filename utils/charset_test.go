@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		name              string
+		head              string
+		contentType       string
+		expectedName      string
+		expectedSource    string
+		expectedConflicts []CharsetDeclaration
+		expectedBOMSize   int
+	}{
+		{
+			name:           "Content-Type header",
+			head:           "<html><head><title>Test</title></head></html>",
+			contentType:    "text/html; charset=Shift_JIS",
+			expectedName:   "shift_jis",
+			expectedSource: CharsetSourceHeader,
+		},
+		{
+			name:           "Meta charset",
+			head:           `<html><head><meta charset="windows-1251"><title>Test</title></head></html>`,
+			contentType:    "text/html",
+			expectedName:   "windows-1251",
+			expectedSource: CharsetSourceMeta,
+		},
+		{
+			name:           "Meta http-equiv",
+			head:           `<html><head><meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1"></head></html>`,
+			expectedName:   "windows-1252",
+			expectedSource: CharsetSourceMeta,
+		},
+		{
+			name:            "Byte order mark",
+			head:            "\xEF\xBB\xBF<html></html>",
+			contentType:     "text/html; charset=utf-8",
+			expectedName:    "utf-8",
+			expectedSource:  CharsetSourceBOM,
+			expectedBOMSize: 3,
+		},
+		{
+			name:           "Header and meta conflict",
+			head:           `<html><head><meta charset="euc-jp"></head></html>`,
+			contentType:    "text/html; charset=utf-8",
+			expectedName:   "utf-8",
+			expectedSource: CharsetSourceHeader,
+			expectedConflicts: []CharsetDeclaration{
+				{Source: CharsetSourceMeta, Label: "euc-jp"},
+			},
+		},
+		{
+			name:           "Unknown declared encoding",
+			head:           `<html><head><meta charset="klingon"></head></html>`,
+			expectedName:   "utf-8",
+			expectedSource: CharsetSourceDefault,
+			expectedConflicts: []CharsetDeclaration{
+				{Source: CharsetSourceMeta, Label: "klingon"},
+			},
+		},
+		{
+			name:           "Undeclared UTF-8",
+			head:           "<html><head><title>Grüße</title></head></html>",
+			expectedName:   "utf-8",
+			expectedSource: CharsetSourceDefault,
+		},
+		{
+			name:           "Undeclared legacy encoding",
+			head:           "<html><head><title>Gr\xFC\xDFe</title></head></html>",
+			expectedName:   "windows-1252",
+			expectedSource: CharsetSourceDefault,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs, _, bomSize := detectCharset([]byte(test.head), test.contentType)
+
+			if cs.Name != test.expectedName || cs.Source != test.expectedSource {
+				t.Errorf("Expected encoding '%s' from '%s', got '%s' from '%s'", test.expectedName, test.expectedSource, cs.Name, cs.Source)
+			}
+			if len(cs.Conflicts) != len(test.expectedConflicts) {
+				t.Fatalf("Expected conflicts %v, got %v", test.expectedConflicts, cs.Conflicts)
+			}
+			for i, conflict := range cs.Conflicts {
+				if conflict != test.expectedConflicts[i] {
+					t.Errorf("Expected conflict %v, got %v", test.expectedConflicts[i], conflict)
+				}
+			}
+			if bomSize != test.expectedBOMSize {
+				t.Errorf("Expected a byte order mark of %d bytes, got %d", test.expectedBOMSize, bomSize)
+			}
+		})
+	}
+}
+
+func TestFetchURLCharset(t *testing.T) {
+	shiftJIS, _ := japanese.ShiftJIS.NewEncoder().String("<title>日本語のページ</title>")
+	windows1251, _ := charmap.Windows1251.NewEncoder().String(`<meta charset="windows-1251"><title>Привет</title>`)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "Shift_JIS declared by the header",
+			contentType: "text/html; charset=Shift_JIS",
+			body:        shiftJIS,
+			expected:    "<title>日本語のページ</title>",
+		},
+		{
+			name:        "windows-1251 declared by the meta element",
+			contentType: "text/html",
+			body:        windows1251,
+			expected:    `<meta charset="windows-1251"><title>Привет</title>`,
+		},
+		{
+			name:        "UTF-8 with a byte order mark",
+			contentType: "text/html",
+			body:        "\xEF\xBB\xBF<title>Grüße</title>",
+			expected:    "<title>Grüße</title>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				io.WriteString(w, test.body)
+			}))
+			defer server.Close()
+
+			body, err := utils.FetchURL(server.URL)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if body != test.expected {
+				t.Errorf("Expected body '%s', got '%s'", test.expected, body)
+			}
+		})
+	}
+}
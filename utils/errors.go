@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the page fetch and the HTML parsing. The returned errors wrap one of them
+// together with the underlying cause, so they are matched with errors.Is.
+var (
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrMissingSchemeOrHost is an ErrInvalidURL
+	ErrMissingSchemeOrHost = fmt.Errorf("%w: missing scheme or host", ErrInvalidURL)
+	// ErrUnsupportedScheme is an ErrInvalidURL reporting a scheme other than http and https
+	ErrUnsupportedScheme = fmt.Errorf("%w: unsupported scheme", ErrInvalidURL)
+	ErrDNS               = errors.New("DNS lookup failed")
+	ErrTimeout           = errors.New("request timed out")
+	ErrTLS               = errors.New("TLS handshake failed")
+	// ErrBlockedAddress reports a request to a private or reserved network address
+	ErrBlockedAddress = errors.New("blocked network address")
+	// ErrFetchFailed reports the network failures other than ErrDNS, ErrTimeout and ErrTLS
+	ErrFetchFailed = errors.New("unable to fetch the URL")
+	// ErrDisallowedByRobots reports a URL disallowed by the robots.txt of its host, which is not requested
+	ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+	ErrTooManyRedirects   = errors.New("too many redirects")
+	ErrRedirectLoop       = errors.New("redirect loop")
+	ErrReadBody           = errors.New("error reading the response body")
+	ErrBodyTooLarge       = errors.New("response body too large")
+	ErrParse              = errors.New("unable to parse the HTML")
+	// ErrInvalidSitemap reports a sitemap file that is not a sitemap or a sitemap index
+	ErrInvalidSitemap = errors.New("invalid sitemap")
+)
+
+// StatusError reports a response with an unexpected status code. errors.Is matches a
+// StatusError of the same status code, or any StatusError if the target status code is zero.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func (e *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && (t.StatusCode == 0 || t.StatusCode == e.StatusCode)
+}
+
+// requestError wraps the error of a failed request with the matching sentinel error
+func requestError(err error) error {
+	var sentinel error
+	switch classifyError(err) {
+	case ErrorClassDNS:
+		sentinel = ErrDNS
+	case ErrorClassTimeout:
+		sentinel = ErrTimeout
+	case ErrorClassTLS:
+		sentinel = ErrTLS
+	case ErrorClassRedirect, ErrorClassBlocked:
+		// The errors of the redirect policy and of the address guard already wrap a sentinel error
+		return err
+	default:
+		sentinel = ErrFetchFailed
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestStatusErrorIs(t *testing.T) {
+	err := fmt.Errorf("fetch failed: %w", &StatusError{StatusCode: 404})
+
+	tests := []struct {
+		name     string
+		target   error
+		expected bool
+	}{
+		{
+			name:     "Same status code",
+			target:   &StatusError{StatusCode: 404},
+			expected: true,
+		},
+		{
+			name:     "Any status code",
+			target:   &StatusError{},
+			expected: true,
+		},
+		{
+			name:     "Other status code",
+			target:   &StatusError{StatusCode: 500},
+			expected: false,
+		},
+		{
+			name:     "Other error",
+			target:   ErrFetchFailed,
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := errors.Is(err, test.target); res != test.expected {
+				t.Errorf("Expected '%t', got '%t'", test.expected, res)
+			}
+		})
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "DNS error",
+			err:      &net.DNSError{Err: "no such host", Name: "invalid.test", IsNotFound: true},
+			expected: ErrDNS,
+		},
+		{
+			name:     "Timeout",
+			err:      fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expected: ErrTimeout,
+		},
+		{
+			name:     "TLS error",
+			err:      x509.UnknownAuthorityError{},
+			expected: ErrTLS,
+		},
+		{
+			name:     "Other network error",
+			err:      errors.New("connection reset"),
+			expected: ErrFetchFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := requestError(test.err)
+
+			if !errors.Is(err, test.expected) {
+				t.Errorf("Expected error %v, got %v", test.expected, err)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("Expected the cause to be kept, got %v", err)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"strings"
 
@@ -18,11 +20,11 @@ func (u *Utils) RenderTemplate(w http.ResponseWriter, r *http.Request, templateP
 	return nil
 }
 
-// ParseHTML parses the HTML content and return as a HTML node tree
-func (u *Utils) ParseHTML(pageHTML string) (*html.Node, error) {
-	doc, err := html.Parse(strings.NewReader(pageHTML))
+// ParseHTML parses the HTML content streamed from the reader and return as a HTML node tree
+func (u *Utils) ParseHTML(r io.Reader) (*html.Node, error) {
+	doc, err := html.Parse(r)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
 	}
 
 	return doc, nil
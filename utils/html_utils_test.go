@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	"golang.org/x/net/html"
@@ -111,7 +112,7 @@ func TestParseHTML(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			node, err := utils.ParseHTML(test.html)
+			node, err := utils.ParseHTML(strings.NewReader(test.html))
 
 			if err != nil && !test.hasError {
 				t.Error("Expected HTML parse no error but error returned")
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Default HTTP client settings used for the zero values of the ClientConfig fields
+const (
+	DefaultConnectTimeout      = 10 * time.Second
+	DefaultReadTimeout         = 15 * time.Second
+	DefaultFetchTimeout        = 30 * time.Second
+	DefaultLinkCheckTimeout    = 5 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultMaxRedirects        = 10
+	DefaultMaxBodySize         = 10 << 20
+	DefaultUserAgent           = "webpage-analyzer/1.0 (+https://github.com/isurukdniss/webpage-analyzer)"
+)
+
+// ClientConfig holds the settings of the HTTP client used to fetch the pages and check the links
+type ClientConfig struct {
+	// ConnectTimeout limits the time to establish a connection. Zero means DefaultConnectTimeout.
+	ConnectTimeout time.Duration
+	// ReadTimeout limits the time to wait for the response headers once the request is sent.
+	// Zero means DefaultReadTimeout.
+	ReadTimeout time.Duration
+	// FetchTimeout limits the total duration of a page fetch attempt, including reading the body.
+	// Zero means DefaultFetchTimeout.
+	FetchTimeout time.Duration
+	// LinkCheckTimeout limits the total duration of a link check request, including redirects.
+	// Zero means DefaultLinkCheckTimeout.
+	LinkCheckTimeout time.Duration
+	// UserAgent is sent with every request. Empty means DefaultUserAgent.
+	UserAgent string
+	// Headers are added to every request
+	Headers map[string]string
+	// Proxy is the URL of the HTTP(S) proxy. Empty means the proxy of the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+	// CABundle is the path of a PEM file with the certificates trusted in addition to the
+	// system ones
+	CABundle string
+	// Insecure disables the verification of the server certificates. Only meant for
+	// staging environments with self-signed certificates.
+	Insecure bool
+	// MaxIdleConns limits the idle connections kept for reuse. Zero means DefaultMaxIdleConns.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the idle connections kept for reuse per host.
+	// Zero means DefaultMaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+	// MaxRedirects limits the redirects followed by a request. Zero means DefaultMaxRedirects.
+	MaxRedirects int
+	// AllowHosts lists the host names, "*.domain" wildcards, IP addresses and CIDR ranges that
+	// may be requested although they resolve to a private or reserved network address
+	AllowHosts []string
+	// DenyHosts lists the host names, "*.domain" wildcards, IP addresses and CIDR ranges that
+	// are never requested. They take precedence over AllowHosts.
+	DenyHosts []string
+	// MaxBodySize limits the decoded size of a fetched page in bytes. Zero means DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+// defaultTransport is used by the zero value of Utils. Building the transport of the default
+// settings cannot fail.
+var defaultTransport, _ = newTransport(ClientConfig{})
+
+// New returns the utilities with an HTTP client of the given settings
+func New(cfg ClientConfig) (*Utils, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Utils{client: cfg, transport: transport, robots: newRobotsCache()}, nil
+}
+
+func newTransport(cfg ClientConfig) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	guard, err := newAddressGuard(cfg.AllowHosts, cfg.DenyHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   withDefault(cfg.ConnectTimeout, DefaultConnectTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	transport.DialContext = guard.dialContext(dialer)
+	transport.ResponseHeaderTimeout = withDefault(cfg.ReadTimeout, DefaultReadTimeout)
+	transport.MaxIdleConns = withDefault(cfg.MaxIdleConns, DefaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = withDefault(cfg.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	proxy := transport.Proxy
+	transport.Proxy = proxyFromContext
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.Insecure,
+	}
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &guardedTransport{Transport: transport, guard: guard, proxy: proxy}, nil
+}
+
+// loadCABundle returns the system certificates with the certificates of the PEM file added
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in the CA bundle")
+	}
+	return pool, nil
+}
+
+// httpClient returns a client of the configured transport with the given total timeout
+func (u *Utils) httpClient(timeout time.Duration) *http.Client {
+	var transport http.RoundTripper = defaultTransport
+	if u.transport != nil {
+		transport = u.transport
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// followRedirects returns a redirect policy that records the followed redirects in the chain.
+// It stops after the configured maximum and when a redirect leads back to a URL of the chain.
+func (u *Utils) followRedirects(chain *[]Redirect) func(req *http.Request, via []*http.Request) error {
+	maxRedirects := withDefault(u.client.MaxRedirects, DefaultMaxRedirects)
+	return func(req *http.Request, via []*http.Request) error {
+		*chain = append(*chain, redirectOf(req))
+		for _, prev := range via {
+			if prev.URL.String() == req.URL.String() {
+				return fmt.Errorf("%w: %s was already visited", ErrRedirectLoop, req.URL)
+			}
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, maxRedirects)
+		}
+		return nil
+	}
+}
+
+// newRequest creates a request with the configured User-Agent and headers
+func (u *Utils) newRequest(ctx context.Context, method string, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, val := range u.client.Headers {
+		req.Header.Set(name, val)
+	}
+	if u.client.UserAgent != "" {
+		req.Header.Set("User-Agent", u.client.UserAgent)
+	} else if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	return req, nil
+}
+
+// withDefault returns the value, or the default if the value is not positive
+func withDefault[T int | int64 | time.Duration](val T, def T) T {
+	if val <= 0 {
+		return def
+	}
+	return val
+}
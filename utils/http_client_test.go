@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewClientHeaders(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		cfg               ClientConfig
+		expectedUserAgent string
+		expectedHeader    string
+	}{
+		{
+			name:              "Default user agent",
+			cfg:               ClientConfig{},
+			expectedUserAgent: DefaultUserAgent,
+		},
+		{
+			name:              "Custom user agent and headers",
+			cfg:               ClientConfig{UserAgent: "nightly-report", Headers: map[string]string{"X-Team": "web"}},
+			expectedUserAgent: "nightly-report",
+			expectedHeader:    "web",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := test.cfg
+			cfg.AllowHosts = loopback
+			u, err := New(cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if _, err := u.FetchURL(server.URL); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if ua := header.Get("User-Agent"); ua != test.expectedUserAgent {
+				t.Errorf("Expected user agent '%s', got '%s'", test.expectedUserAgent, ua)
+			}
+			if val := header.Get("X-Team"); val != test.expectedHeader {
+				t.Errorf("Expected header '%s', got '%s'", test.expectedHeader, val)
+			}
+		})
+	}
+}
+
+func TestNewClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of the requested page
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	// example.test is not resolved, so it is allowed by name
+	u, err := New(ClientConfig{Proxy: proxy.URL, AllowHosts: []string{"example.test"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := u.CheckLink(context.Background(), "http://example.test/page")
+
+	if !res.Accessible {
+		t.Errorf("Expected the link to be checked through the proxy, got %v", res)
+	}
+	if proxied != "http://example.test/page" {
+		t.Errorf("Expected the proxy to receive 'http://example.test/page', got '%s'", proxied)
+	}
+}
+
+func TestNewClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		cfg                ClientConfig
+		expectedAccessible bool
+		expectedErrorClass string
+	}{
+		{
+			name:               "Unknown certificate authority",
+			cfg:                ClientConfig{},
+			expectedErrorClass: ErrorClassTLS,
+		},
+		{
+			name:               "Custom CA bundle",
+			cfg:                ClientConfig{CABundle: bundle},
+			expectedAccessible: true,
+		},
+		{
+			name:               "Insecure mode",
+			cfg:                ClientConfig{Insecure: true},
+			expectedAccessible: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := test.cfg
+			cfg.AllowHosts = loopback
+			u, err := New(cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			res := u.CheckLink(context.Background(), server.URL)
+
+			if res.Accessible != test.expectedAccessible {
+				t.Errorf("Expected accessible %v, got %v (%s)", test.expectedAccessible, res.Accessible, res.Error)
+			}
+			if res.ErrorClass != test.expectedErrorClass {
+				t.Errorf("Expected error class '%s', got '%s'", test.expectedErrorClass, res.ErrorClass)
+			}
+		})
+	}
+}
+
+func TestNewClientInvalidConfig(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("no certificates"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  ClientConfig
+	}{
+		{
+			name: "Invalid proxy URL",
+			cfg:  ClientConfig{Proxy: "proxy:3128"},
+		},
+		{
+			name: "Missing CA bundle",
+			cfg:  ClientConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+		{
+			name: "CA bundle without certificates",
+			cfg:  ClientConfig{CABundle: empty},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(test.cfg); err == nil {
+				t.Error("Expected an error but no error returned")
+			}
+		})
+	}
+}
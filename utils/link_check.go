@@ -0,0 +1,351 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxProbeBodySize is the number of bytes requested and read by the GET fallback of a link check
+const maxProbeBodySize = 1024
+
+// DefaultMaxRetryAfter is the longest Retry-After delay a link check waits for before retrying
+const DefaultMaxRetryAfter = 10 * time.Second
+
+// Error classes of a failed link check
+const (
+	ErrorClassDNS               = "dns"
+	ErrorClassTimeout           = "timeout"
+	ErrorClassTLS               = "tls"
+	ErrorClassConnectionRefused = "connection_refused"
+	// ErrorClassRedirect reports too many redirects or a redirect loop
+	ErrorClassRedirect = "redirect"
+	// ErrorClassBlocked reports a link to a private or reserved network address
+	ErrorClassBlocked = "blocked"
+	ErrorClassNetwork = "network"
+	ErrorClassHTTP4xx = "http_4xx"
+	ErrorClassHTTP5xx = "http_5xx"
+)
+
+// States of a checked link. Only broken and blocked links are reported as inaccessible.
+const (
+	LinkStateOK          = "ok"
+	LinkStateBroken      = "broken"
+	LinkStateRestricted  = "restricted"
+	LinkStateRateLimited = "rate_limited"
+	// LinkStateBlocked links point to a private or reserved network address and are not checked
+	LinkStateBlocked = "blocked"
+	// LinkStateDisallowed links are disallowed by the robots.txt of their host and are not checked
+	LinkStateDisallowed = "disallowed"
+)
+
+// LinkStates lists the link states the status codes can be mapped to
+var LinkStates = []string{LinkStateOK, LinkStateBroken, LinkStateRestricted, LinkStateRateLimited}
+
+// DefaultLinkStatusStates returns the states of the status codes that do not denote a broken link
+// although they are errors: the link exists but cannot be accessed by the analyzer
+func DefaultLinkStatusStates() map[int]string {
+	return map[int]string{
+		http.StatusUnauthorized:    LinkStateRestricted,
+		http.StatusForbidden:       LinkStateRestricted,
+		http.StatusTooManyRequests: LinkStateRateLimited,
+	}
+}
+
+// headRejectedStatus holds the status codes with which servers commonly reject HEAD
+// requests of resources that are served fine with GET
+var headRejectedStatus = map[int]bool{
+	http.StatusBadRequest:       true,
+	http.StatusForbidden:        true,
+	http.StatusMethodNotAllowed: true,
+	http.StatusNotImplemented:   true,
+}
+
+// Redirect is a single hop of a redirect chain
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+// LinkCheck is the detailed outcome of checking the accessibility of a link
+type LinkCheck struct {
+	URL string `json:"url"`
+	// Accessible is false if the link is broken, or blocked because it points to a private or
+	// reserved network address
+	Accessible bool   `json:"accessible"`
+	State      string `json:"state"`
+	// Method is the method of the final request, GET if the server rejected the HEAD request
+	Method string `json:"method,omitempty"`
+	// StatusCode is the status code of the final response, zero if no response was received
+	StatusCode int        `json:"status_code,omitempty"`
+	Redirects  []Redirect `json:"redirects,omitempty"`
+	// RetryAfterMS is the delay requested by the Retry-After header of the final response
+	RetryAfterMS int64 `json:"retry_after_ms,omitempty"`
+	// Retries is the number of times the check was retried after a transient failure
+	Retries int `json:"retries"`
+	// ErrorClass classifies why the link is broken, see the ErrorClass constants
+	ErrorClass     string `json:"error_class,omitempty"`
+	Error          string `json:"error,omitempty"`
+	ResponseTimeMS int64  `json:"response_time_ms"`
+	// RobotsDisallowed reports that the robots.txt of the host disallows the link
+	RobotsDisallowed bool `json:"robots_disallowed,omitempty"`
+}
+
+// CheckLink checks the accessibility of the link and reports its state, the final status
+// code, the redirect chain, the response time and the class of the error if the link is broken.
+// The link is requested with HEAD, and with a GET of its first bytes if the server rejects
+// the HEAD request. Transient failures are retried according to the retry policy. The robots.txt
+// of the host is followed according to the robots policy.
+// Assumption: If the request times out after the link check timeout then the url is inaccessible
+func (u *Utils) CheckLink(ctx context.Context, link string) LinkCheck {
+	res := LinkCheck{URL: link}
+
+	var robots *robotsHost
+	if target, err := url.Parse(link); err == nil && target.Host != "" {
+		// Fails only when the context is canceled, which fails the request of the link as well
+		robots, _ = u.robotsRules(ctx, target)
+		res.RobotsDisallowed = !robots.allowed(target)
+	}
+	if res.RobotsDisallowed && u.Robots.skip() {
+		// The link is not requested, so it is not known to be broken
+		res.State = LinkStateDisallowed
+		res.Accessible = true
+		return res
+	}
+
+	var statusCode int
+	var retryAfter time.Duration
+	var err error
+	for attempt := 1; ; attempt++ {
+		statusCode, retryAfter, err = u.probeLink(ctx, link, robots, &res)
+
+		delay, retry := u.Retry.nextDelay(attempt, statusCode, retryAfter, err, u.maxRetryAfter())
+		if !retry {
+			break
+		}
+		// A canceled backoff aborts the check with the error of the context
+		if err = sleepContext(ctx, delay); err != nil {
+			break
+		}
+		res.Retries++
+	}
+
+	if err != nil {
+		res.State = LinkStateBroken
+		res.ErrorClass = classifyError(err)
+		res.Error = err.Error()
+		if res.ErrorClass == ErrorClassBlocked {
+			// The link may be fine but cannot be reached by the analyzer, so it stays inaccessible
+			res.State = LinkStateBlocked
+		}
+		return res
+	}
+
+	res.StatusCode = statusCode
+	if retryAfter > 0 {
+		res.RetryAfterMS = retryAfter.Milliseconds()
+	}
+	res.State = u.linkState(statusCode)
+	res.Accessible = res.State != LinkStateBroken
+	if !res.Accessible {
+		res.ErrorClass = classifyStatus(statusCode)
+	}
+
+	return res
+}
+
+// probeLink requests the link with HEAD, falling back to a limited GET if the server rejects
+// the HEAD request. It returns the final status code and the Retry-After delay, which is
+// negative if the response has none.
+func (u *Utils) probeLink(ctx context.Context, link string, robots *robotsHost, res *LinkCheck) (int, time.Duration, error) {
+	if err := robots.wait(ctx, u.maxCrawlDelay()); err != nil {
+		return 0, 0, err
+	}
+
+	start := time.Now()
+	defer func() {
+		res.ResponseTimeMS = time.Since(start).Milliseconds()
+	}()
+
+	res.Method = http.MethodHead
+	res.Redirects = nil
+	resp, err := u.sendLinkRequest(ctx, http.MethodHead, link, res)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp.Body.Close()
+
+	if headRejectedStatus[resp.StatusCode] {
+		if err := robots.wait(ctx, u.maxCrawlDelay()); err != nil {
+			return 0, 0, err
+		}
+		res.Method = http.MethodGet
+		res.Redirects = nil
+		resp, err = u.sendLinkRequest(ctx, http.MethodGet, link, res)
+		if err != nil {
+			return 0, 0, err
+		}
+		// Only the first bytes are read, the server may ignore the Range header
+		io.CopyN(io.Discard, resp.Body, maxProbeBodySize)
+		resp.Body.Close()
+	}
+
+	return resp.StatusCode, retryAfterOf(resp), nil
+}
+
+// sendLinkRequest sends a request of a link check and records the followed redirects
+func (u *Utils) sendLinkRequest(ctx context.Context, method string, link string, res *LinkCheck) (*http.Response, error) {
+	client := u.httpClient(withDefault(u.client.LinkCheckTimeout, DefaultLinkCheckTimeout))
+	client.CheckRedirect = u.followRedirects(&res.Redirects)
+
+	req, err := u.newRequest(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", maxProbeBodySize-1))
+	}
+
+	return client.Do(req)
+}
+
+// linkState returns the state of a link that responded with the given status code
+func (u *Utils) linkState(statusCode int) string {
+	states := u.LinkStatusStates
+	if states == nil {
+		states = DefaultLinkStatusStates()
+	}
+	if state, ok := states[statusCode]; ok {
+		return state
+	}
+
+	// The resource exists but is shorter than the range requested by the GET fallback
+	if statusCode == http.StatusRequestedRangeNotSatisfiable {
+		return LinkStateOK
+	}
+	if classifyStatus(statusCode) != "" {
+		return LinkStateBroken
+	}
+	return LinkStateOK
+}
+
+func (u *Utils) maxRetryAfter() time.Duration {
+	if u.MaxRetryAfter > 0 {
+		return u.MaxRetryAfter
+	}
+	return DefaultMaxRetryAfter
+}
+
+// retryAfterOf returns the delay of the Retry-After header of the response, given either
+// in seconds or as an HTTP date, or -1 if the header is missing or invalid
+func retryAfterOf(resp *http.Response) time.Duration {
+	val := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if val == "" {
+		return -1
+	}
+
+	if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(val); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return -1
+}
+
+// sleepContext waits for the given duration or until the context is canceled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// redirectOf returns the redirect hop that led to the given request
+func redirectOf(req *http.Request) Redirect {
+	r := Redirect{Location: req.URL.String()}
+	if req.Response != nil {
+		r.StatusCode = req.Response.StatusCode
+		r.URL = req.Response.Request.URL.String()
+	}
+	return r
+}
+
+// classifyStatus returns the error class of an HTTP status code, or an empty string
+// if the status code denotes an accessible link
+func classifyStatus(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return ErrorClassHTTP5xx
+	case statusCode >= 400:
+		return ErrorClassHTTP4xx
+	}
+	return ""
+}
+
+// classifyError returns the error class of a failed request
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	if isTLSError(err) {
+		return ErrorClassTLS
+	}
+
+	if errors.Is(err, ErrBlockedAddress) {
+		return ErrorClassBlocked
+	}
+
+	if errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrRedirectLoop) {
+		return ErrorClassRedirect
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorClassConnectionRefused
+	}
+
+	return ErrorClassNetwork
+}
+
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCheckLink(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/head-not-allowed", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Range") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	var throttled atomic.Int32
+	mux.HandleFunc("/throttled", func(w http.ResponseWriter, r *http.Request) {
+		if throttled.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	var unavailable atomic.Int32
+	mux.HandleFunc("/unavailable", func(w http.ResponseWriter, r *http.Request) {
+		if unavailable.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/temporary", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		path               string
+		states             map[int]string
+		expectedAccessible bool
+		expectedState      string
+		expectedMethod     string
+		expectedStatusCode int
+		expectedErrorClass string
+		expectedRetryAfter int64
+		expectedRetries    int
+		expectedRedirects  []Redirect
+	}{
+		{
+			name:               "Accessible link",
+			path:               "/ok",
+			expectedAccessible: true,
+			expectedState:      LinkStateOK,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Client error",
+			path:               "/missing",
+			expectedState:      LinkStateBroken,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusNotFound,
+			expectedErrorClass: ErrorClassHTTP4xx,
+		},
+		{
+			name:               "Server error",
+			path:               "/broken",
+			expectedState:      LinkStateBroken,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedErrorClass: ErrorClassHTTP5xx,
+		},
+		{
+			name:               "HEAD rejected, GET accepted",
+			path:               "/head-not-allowed",
+			expectedAccessible: true,
+			expectedState:      LinkStateOK,
+			expectedMethod:     http.MethodGet,
+			expectedStatusCode: http.StatusPartialContent,
+		},
+		{
+			name:               "Forbidden is restricted",
+			path:               "/forbidden",
+			expectedAccessible: true,
+			expectedState:      LinkStateRestricted,
+			expectedMethod:     http.MethodGet,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Unauthorized is restricted",
+			path:               "/login",
+			expectedAccessible: true,
+			expectedState:      LinkStateRestricted,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Forbidden configured as broken",
+			path:               "/forbidden",
+			states:             map[int]string{},
+			expectedState:      LinkStateBroken,
+			expectedMethod:     http.MethodGet,
+			expectedStatusCode: http.StatusForbidden,
+			expectedErrorClass: ErrorClassHTTP4xx,
+		},
+		{
+			name:               "Retried after the Retry-After delay",
+			path:               "/throttled",
+			expectedAccessible: true,
+			expectedState:      LinkStateOK,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusOK,
+			expectedRetries:    1,
+		},
+		{
+			name:               "Retried after transient failures",
+			path:               "/unavailable",
+			expectedAccessible: true,
+			expectedState:      LinkStateOK,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusOK,
+			expectedRetries:    2,
+		},
+		{
+			name:               "Retry-After longer than the maximum",
+			path:               "/busy",
+			expectedAccessible: true,
+			expectedState:      LinkStateRateLimited,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedRetryAfter: 120000,
+		},
+		{
+			name:               "Redirect chain",
+			path:               "/moved",
+			expectedAccessible: true,
+			expectedState:      LinkStateOK,
+			expectedMethod:     http.MethodHead,
+			expectedStatusCode: http.StatusOK,
+			expectedRedirects: []Redirect{
+				{URL: server.URL + "/moved", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/temporary"},
+				{URL: server.URL + "/temporary", StatusCode: http.StatusFound, Location: server.URL + "/ok"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u := &Utils{LinkStatusStates: test.states, Retry: RetryPolicy{BaseDelay: time.Millisecond}}
+			res := u.CheckLink(context.Background(), server.URL+test.path)
+
+			if res.Accessible != test.expectedAccessible {
+				t.Errorf("Expected accessible %v, got %v", test.expectedAccessible, res.Accessible)
+			}
+			if res.State != test.expectedState {
+				t.Errorf("Expected state '%s', got '%s'", test.expectedState, res.State)
+			}
+			if res.Method != test.expectedMethod {
+				t.Errorf("Expected method '%s', got '%s'", test.expectedMethod, res.Method)
+			}
+			if res.RetryAfterMS != test.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %dms, got %dms", test.expectedRetryAfter, res.RetryAfterMS)
+			}
+			if res.Retries != test.expectedRetries {
+				t.Errorf("Expected %d retries, got %d", test.expectedRetries, res.Retries)
+			}
+			if res.StatusCode != test.expectedStatusCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedStatusCode, res.StatusCode)
+			}
+			if res.ErrorClass != test.expectedErrorClass {
+				t.Errorf("Expected error class '%s', got '%s'", test.expectedErrorClass, res.ErrorClass)
+			}
+			if fmt.Sprint(res.Redirects) != fmt.Sprint(test.expectedRedirects) {
+				t.Errorf("Expected redirects %v, got %v", test.expectedRedirects, res.Redirects)
+			}
+		})
+	}
+}
+
+func TestCheckLinkConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	link := server.URL
+	server.Close()
+
+	res := (&Utils{}).CheckLink(context.Background(), link)
+
+	if res.Accessible {
+		t.Error("Expected the link to be inaccessible")
+	}
+	if res.ErrorClass != ErrorClassConnectionRefused {
+		t.Errorf("Expected error class '%s', got '%s'", ErrorClassConnectionRefused, res.ErrorClass)
+	}
+	if res.Error == "" {
+		t.Error("Expected the error message to be reported")
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedClass string
+	}{
+		{
+			name:          "DNS error",
+			err:           &net.DNSError{Err: "no such host", Name: "invalid.test", IsNotFound: true},
+			expectedClass: ErrorClassDNS,
+		},
+		{
+			name:          "Deadline exceeded",
+			err:           fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expectedClass: ErrorClassTimeout,
+		},
+		{
+			name:          "Unknown certificate authority",
+			err:           x509.UnknownAuthorityError{},
+			expectedClass: ErrorClassTLS,
+		},
+		{
+			name:          "Connection refused",
+			err:           &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			expectedClass: ErrorClassConnectionRefused,
+		},
+		{
+			name:          "Other network error",
+			err:           errors.New("connection reset"),
+			expectedClass: ErrorClassNetwork,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if class := classifyError(test.err); class != test.expectedClass {
+				t.Errorf("Expected error class '%s', got '%s'", test.expectedClass, class)
+			}
+		})
+	}
+}
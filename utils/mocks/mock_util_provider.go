@@ -10,9 +10,12 @@
 package mocks
 
 import (
+	context "context"
+	io "io"
 	http "net/http"
 	reflect "reflect"
 
+	utils "github.com/isurukdniss/webpage-analyzer/utils"
 	gomock "go.uber.org/mock/gomock"
 	html "golang.org/x/net/html"
 )
@@ -40,6 +43,35 @@ func (m *MockUtilProvider) EXPECT() *MockUtilProviderMockRecorder {
 	return m.recorder
 }
 
+// CheckLink mocks base method.
+func (m *MockUtilProvider) CheckLink(ctx context.Context, link string) utils.LinkCheck {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLink", ctx, link)
+	ret0, _ := ret[0].(utils.LinkCheck)
+	return ret0
+}
+
+// CheckLink indicates an expected call of CheckLink.
+func (mr *MockUtilProviderMockRecorder) CheckLink(ctx, link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLink", reflect.TypeOf((*MockUtilProvider)(nil).CheckLink), ctx, link)
+}
+
+// DiscoverSitemaps mocks base method.
+func (m *MockUtilProvider) DiscoverSitemaps(ctx context.Context, siteURL string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscoverSitemaps", ctx, siteURL)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiscoverSitemaps indicates an expected call of DiscoverSitemaps.
+func (mr *MockUtilProviderMockRecorder) DiscoverSitemaps(ctx, siteURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverSitemaps", reflect.TypeOf((*MockUtilProvider)(nil).DiscoverSitemaps), ctx, siteURL)
+}
+
 // ExtractAttribute mocks base method.
 func (m *MockUtilProvider) ExtractAttribute(n *html.Node, attr string) string {
 	m.ctrl.T.Helper()
@@ -82,6 +114,36 @@ func (mr *MockUtilProviderMockRecorder) ExtractTitle(n any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractTitle", reflect.TypeOf((*MockUtilProvider)(nil).ExtractTitle), n)
 }
 
+// FetchPage mocks base method.
+func (m *MockUtilProvider) FetchPage(ctx context.Context, url string) (*utils.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPage", ctx, url)
+	ret0, _ := ret[0].(*utils.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPage indicates an expected call of FetchPage.
+func (mr *MockUtilProviderMockRecorder) FetchPage(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPage", reflect.TypeOf((*MockUtilProvider)(nil).FetchPage), ctx, url)
+}
+
+// FetchSitemap mocks base method.
+func (m *MockUtilProvider) FetchSitemap(ctx context.Context, sitemapURL string) (*utils.Sitemap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchSitemap", ctx, sitemapURL)
+	ret0, _ := ret[0].(*utils.Sitemap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchSitemap indicates an expected call of FetchSitemap.
+func (mr *MockUtilProviderMockRecorder) FetchSitemap(ctx, sitemapURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSitemap", reflect.TypeOf((*MockUtilProvider)(nil).FetchSitemap), ctx, sitemapURL)
+}
+
 // FetchURL mocks base method.
 func (m *MockUtilProvider) FetchURL(url string) (string, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +159,21 @@ func (mr *MockUtilProviderMockRecorder) FetchURL(url any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchURL", reflect.TypeOf((*MockUtilProvider)(nil).FetchURL), url)
 }
 
+// FetchURLContext mocks base method.
+func (m *MockUtilProvider) FetchURLContext(ctx context.Context, url string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchURLContext", ctx, url)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchURLContext indicates an expected call of FetchURLContext.
+func (mr *MockUtilProviderMockRecorder) FetchURLContext(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchURLContext", reflect.TypeOf((*MockUtilProvider)(nil).FetchURLContext), ctx, url)
+}
+
 // HasLoginForm mocks base method.
 func (m *MockUtilProvider) HasLoginForm(n *html.Node) bool {
 	m.ctrl.T.Helper()
@@ -139,19 +216,33 @@ func (mr *MockUtilProviderMockRecorder) IsLinkAccessible(link any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLinkAccessible", reflect.TypeOf((*MockUtilProvider)(nil).IsLinkAccessible), link)
 }
 
+// IsLinkAccessibleContext mocks base method.
+func (m *MockUtilProvider) IsLinkAccessibleContext(ctx context.Context, link string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsLinkAccessibleContext", ctx, link)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsLinkAccessibleContext indicates an expected call of IsLinkAccessibleContext.
+func (mr *MockUtilProviderMockRecorder) IsLinkAccessibleContext(ctx, link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLinkAccessibleContext", reflect.TypeOf((*MockUtilProvider)(nil).IsLinkAccessibleContext), ctx, link)
+}
+
 // ParseHTML mocks base method.
-func (m *MockUtilProvider) ParseHTML(pageHTML string) (*html.Node, error) {
+func (m *MockUtilProvider) ParseHTML(r io.Reader) (*html.Node, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseHTML", pageHTML)
+	ret := m.ctrl.Call(m, "ParseHTML", r)
 	ret0, _ := ret[0].(*html.Node)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ParseHTML indicates an expected call of ParseHTML.
-func (mr *MockUtilProviderMockRecorder) ParseHTML(pageHTML any) *gomock.Call {
+func (mr *MockUtilProviderMockRecorder) ParseHTML(r any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseHTML", reflect.TypeOf((*MockUtilProvider)(nil).ParseHTML), pageHTML)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseHTML", reflect.TypeOf((*MockUtilProvider)(nil).ParseHTML), r)
 }
 
 // RenderTemplate mocks base method.
@@ -167,3 +258,18 @@ func (mr *MockUtilProviderMockRecorder) RenderTemplate(w, r, templatePath, data
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderTemplate", reflect.TypeOf((*MockUtilProvider)(nil).RenderTemplate), w, r, templatePath, data)
 }
+
+// ResolveURL mocks base method.
+func (m *MockUtilProvider) ResolveURL(baseURL, href string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveURL", baseURL, href)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveURL indicates an expected call of ResolveURL.
+func (mr *MockUtilProviderMockRecorder) ResolveURL(baseURL, href any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveURL", reflect.TypeOf((*MockUtilProvider)(nil).ResolveURL), baseURL, href)
+}
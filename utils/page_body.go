@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// pageBody streams the body of a fetched page. It decompresses a gzip encoded body, counts the
+// transferred and decoded bytes into the page and fails with ErrBodyTooLarge once more than
+// the maximum body size is decoded.
+type pageBody struct {
+	page    *Page
+	raw     io.ReadCloser
+	decoded io.Reader
+	maxSize int64
+}
+
+func newPageBody(resp *http.Response, page *Page, maxSize int64) (*pageBody, error) {
+	b := &pageBody{page: page, raw: resp.Body, maxSize: maxSize}
+	b.decoded = &countingReader{r: resp.Body, n: &page.TransferredBytes}
+
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(b.decoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrReadBody, err)
+		}
+		b.decoded = gz
+	}
+	return b, nil
+}
+
+func (b *pageBody) Read(p []byte) (int, error) {
+	if b.page.DecodedBytes > b.maxSize {
+		return 0, b.tooLarge()
+	}
+
+	// One byte more than the maximum is read to tell a body of the maximum size from a larger one
+	if remaining := b.maxSize + 1 - b.page.DecodedBytes; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := b.decoded.Read(p)
+	b.page.DecodedBytes += int64(n)
+	if b.page.DecodedBytes > b.maxSize {
+		return n, b.tooLarge()
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, fmt.Errorf("%w: %w", ErrReadBody, err)
+	}
+	return n, err
+}
+
+func (b *pageBody) Close() error {
+	return b.raw.Close()
+}
+
+func (b *pageBody) tooLarge() error {
+	return fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, b.maxSize)
+}
+
+// countingReader adds the number of bytes read to n
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
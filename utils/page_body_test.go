@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPageBody(t *testing.T) {
+	page := strings.Repeat("<p>Hello</p>", 100)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(page))
+	gz.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, page)
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected the request to accept gzip, got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		// Flushing sends the body chunked, without a Content-Length
+		for i := 0; i < 100; i++ {
+			io.WriteString(w, "<p>Hello</p>")
+			w.(http.Flusher).Flush()
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name                string
+		path                string
+		maxBodySize         int64
+		expectedTransferred int64
+		expectedDecoded     int64
+		expectedErr         error
+	}{
+		{
+			name:                "Plain body",
+			path:                "/plain",
+			expectedTransferred: int64(len(page)),
+			expectedDecoded:     int64(len(page)),
+		},
+		{
+			name:                "Gzip encoded body",
+			path:                "/gzip",
+			expectedTransferred: int64(compressed.Len()),
+			expectedDecoded:     int64(len(page)),
+		},
+		{
+			name:                "Body of the maximum size",
+			path:                "/plain",
+			maxBodySize:         int64(len(page)),
+			expectedTransferred: int64(len(page)),
+			expectedDecoded:     int64(len(page)),
+		},
+		{
+			name:        "Content-Length above the maximum size",
+			path:        "/plain",
+			maxBodySize: 100,
+			expectedErr: ErrBodyTooLarge,
+		},
+		{
+			name:            "Streamed body above the maximum size",
+			path:            "/stream",
+			maxBodySize:     100,
+			expectedDecoded: 101,
+			expectedErr:     ErrBodyTooLarge,
+		},
+		{
+			name:            "Decoded body above the maximum size",
+			path:            "/gzip",
+			maxBodySize:     100,
+			expectedDecoded: 101,
+			expectedErr:     ErrBodyTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := New(ClientConfig{MaxBodySize: test.maxBodySize, AllowHosts: loopback})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			res, err := u.FetchPage(context.Background(), server.URL+test.path)
+			if err == nil {
+				var body []byte
+				body, err = io.ReadAll(res.Body)
+				res.Body.Close()
+				if err == nil && string(body) != page {
+					t.Errorf("Expected the decoded page, got '%s'", body)
+				}
+			}
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected error '%v', got '%v'", test.expectedErr, err)
+			}
+			if test.expectedTransferred != 0 && res.TransferredBytes != test.expectedTransferred {
+				t.Errorf("Expected %d transferred bytes, got %d", test.expectedTransferred, res.TransferredBytes)
+			}
+			if res.DecodedBytes != test.expectedDecoded {
+				t.Errorf("Expected %d decoded bytes, got %d", test.expectedDecoded, res.DecodedBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Default retry policy used for the zero values of the RetryPolicy fields
+const (
+	DefaultMaxAttempts    = 3
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Second
+)
+
+// ErrorClasses lists the error classes of the requests that failed without a response, which
+// are the classes RetryableErrorClasses may hold. The responses with an error status are
+// retried by their status code, see RetryableStatusCodes.
+var ErrorClasses = []string{
+	ErrorClassDNS,
+	ErrorClassTimeout,
+	ErrorClassTLS,
+	ErrorClassConnectionRefused,
+	ErrorClassRedirect,
+	ErrorClassBlocked,
+	ErrorClassNetwork,
+}
+
+// DefaultRetryableErrorClasses returns the error classes of the failures that are usually transient
+func DefaultRetryableErrorClasses() []string {
+	return []string{ErrorClassTimeout, ErrorClassNetwork}
+}
+
+// DefaultRetryableStatusCodes returns the status codes of the responses that are usually transient
+func DefaultRetryableStatusCodes() []int {
+	return []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+}
+
+// RetryPolicy defines when and how often the page fetch and the link checks are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Zero means DefaultMaxAttempts, one disables the retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled for every following retry.
+	// Zero means DefaultRetryBaseDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. Zero means DefaultRetryMaxDelay.
+	MaxDelay time.Duration
+	// RetryableErrorClasses are the error classes of the failed requests that are retried.
+	// Nil means DefaultRetryableErrorClasses.
+	RetryableErrorClasses []string
+	// RetryableStatusCodes are the status codes of the responses that are retried.
+	// Nil means DefaultRetryableStatusCodes.
+	RetryableStatusCodes []int
+}
+
+// nextDelay returns how long to wait before retrying a failed attempt, and false if the attempt
+// must not be retried. A 429 or 503 response with a Retry-After of at most maxRetryAfter is
+// always retried after the requested delay. retryAfter is negative if the response has none.
+func (p RetryPolicy) nextDelay(attempt int, statusCode int, retryAfter time.Duration, err error, maxRetryAfter time.Duration) (time.Duration, bool) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+
+	if err != nil {
+		classes := p.RetryableErrorClasses
+		if classes == nil {
+			classes = DefaultRetryableErrorClasses()
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, ErrBodyTooLarge) || !slices.Contains(classes, classifyError(err)) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+
+	if (statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable) && retryAfter >= 0 {
+		if retryAfter > maxRetryAfter {
+			return 0, false
+		}
+		return retryAfter, true
+	}
+
+	statusCodes := p.RetryableStatusCodes
+	if statusCodes == nil {
+		statusCodes = DefaultRetryableStatusCodes()
+	}
+	if slices.Contains(statusCodes, statusCode) {
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff returns the exponential delay before the retry of the given attempt. Half of the
+// delay is random so that the retries of concurrent requests are spread out.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
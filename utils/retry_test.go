@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNextDelay(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        RetryPolicy
+		attempt       int
+		statusCode    int
+		retryAfter    time.Duration
+		err           error
+		expectedRetry bool
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "Timeout is retried",
+			attempt:       1,
+			err:           fmt.Errorf("request failed: %w", context.DeadlineExceeded),
+			expectedRetry: true,
+		},
+		{
+			name:          "DNS error is not retried",
+			attempt:       1,
+			err:           &net.DNSError{Err: "no such host", Name: "invalid.test", IsNotFound: true},
+			expectedRetry: false,
+		},
+		{
+			name:          "Canceled request is not retried",
+			attempt:       1,
+			err:           fmt.Errorf("request failed: %w", context.Canceled),
+			expectedRetry: false,
+		},
+		{
+			name:          "Configured error class is retried",
+			policy:        RetryPolicy{RetryableErrorClasses: []string{ErrorClassDNS}},
+			attempt:       1,
+			err:           &net.DNSError{Err: "no such host", Name: "invalid.test", IsNotFound: true},
+			expectedRetry: true,
+		},
+		{
+			name:          "Bad gateway is retried",
+			attempt:       1,
+			statusCode:    http.StatusBadGateway,
+			retryAfter:    -1,
+			expectedRetry: true,
+		},
+		{
+			name:          "Not found is not retried",
+			attempt:       1,
+			statusCode:    http.StatusNotFound,
+			retryAfter:    -1,
+			expectedRetry: false,
+		},
+		{
+			name:          "Configured status codes replace the defaults",
+			policy:        RetryPolicy{RetryableStatusCodes: []int{http.StatusNotFound}},
+			attempt:       1,
+			statusCode:    http.StatusBadGateway,
+			retryAfter:    -1,
+			expectedRetry: false,
+		},
+		{
+			name:          "Retry-After is honoured",
+			attempt:       1,
+			statusCode:    http.StatusTooManyRequests,
+			retryAfter:    3 * time.Second,
+			expectedRetry: true,
+			expectedDelay: 3 * time.Second,
+		},
+		{
+			name:          "Retry-After longer than the maximum",
+			attempt:       1,
+			statusCode:    http.StatusServiceUnavailable,
+			retryAfter:    time.Minute,
+			expectedRetry: false,
+		},
+		{
+			name:          "Attempts exhausted",
+			attempt:       DefaultMaxAttempts,
+			statusCode:    http.StatusBadGateway,
+			retryAfter:    -1,
+			expectedRetry: false,
+		},
+		{
+			name:          "Retries disabled",
+			policy:        RetryPolicy{MaxAttempts: 1},
+			attempt:       1,
+			err:           errors.New("connection reset by peer"),
+			expectedRetry: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delay, retry := test.policy.nextDelay(test.attempt, test.statusCode, test.retryAfter, test.err, DefaultMaxRetryAfter)
+
+			if retry != test.expectedRetry {
+				t.Fatalf("Expected retry %v, got %v", test.expectedRetry, retry)
+			}
+			if test.expectedDelay != 0 && delay != test.expectedDelay {
+				t.Errorf("Expected delay %s, got %s", test.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 100 * time.Millisecond},
+		{attempt: 2, expected: 200 * time.Millisecond},
+		{attempt: 3, expected: 400 * time.Millisecond},
+		{attempt: 10, expected: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.attempt), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				// Half of the delay is random
+				if delay := p.backoff(test.attempt); delay < test.expected/2 || delay > test.expected {
+					t.Fatalf("Expected a delay between %s and %s, got %s", test.expected/2, test.expected, delay)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modes of the robots.txt policy
+const (
+	// RobotsOff does not fetch robots.txt
+	RobotsOff = "off"
+	// RobotsReport reports the disallowed URLs but requests them anyway
+	RobotsReport = "report"
+	// RobotsSkip reports the disallowed URLs and does not request them
+	RobotsSkip = "skip"
+)
+
+// RobotsModes lists the modes of the robots.txt policy
+var RobotsModes = []string{RobotsOff, RobotsReport, RobotsSkip}
+
+// Default settings of the robots.txt policy
+const (
+	DefaultMaxCrawlDelay = 30 * time.Second
+	// robotsCacheTTL is how long the rules of a host are cached
+	robotsCacheTTL = 24 * time.Hour
+	// robotsErrorTTL is how long the rules of a host whose robots.txt failed with a server
+	// error are cached, so that the file is requested again soon
+	robotsErrorTTL = time.Minute
+	// maxRobotsSize is the size of robots.txt read, the rest of the file is ignored
+	maxRobotsSize = 500 << 10
+)
+
+// RobotsPolicy is how the page fetch and the link checks follow the robots.txt of the hosts.
+// The zero value skips the URLs disallowed by robots.txt.
+type RobotsPolicy struct {
+	// Mode is RobotsOff, RobotsReport or RobotsSkip. Empty means RobotsSkip.
+	Mode string
+	// MaxCrawlDelay caps the Crawl-delay waited between two requests to a host.
+	// Zero means DefaultMaxCrawlDelay.
+	MaxCrawlDelay time.Duration
+}
+
+func (p RobotsPolicy) enabled() bool {
+	return p.Mode != RobotsOff
+}
+
+// skip reports whether the disallowed URLs are not requested
+func (p RobotsPolicy) skip() bool {
+	return p.Mode == RobotsSkip || p.Mode == ""
+}
+
+// robotsRule is an Allow or Disallow rule of robots.txt
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsGroup holds the rules of robots.txt that apply to a user agent
+type robotsGroup struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsFile holds the groups of robots.txt by lower-cased user agent and the URLs of the
+// sitemaps it lists
+type robotsFile struct {
+	groups   map[string]*robotsGroup
+	sitemaps []string
+}
+
+// disallowAll is the group of a host whose robots.txt failed with a server error
+var disallowAll = &robotsGroup{rules: []robotsRule{{pattern: "/"}}}
+
+// parseRobots parses robots.txt. The consecutive User-agent lines start a group, the rules
+// that follow apply to all of them and the groups of the same user agent are merged.
+func parseRobots(r io.Reader) *robotsFile {
+	file := &robotsFile{groups: make(map[string]*robotsGroup)}
+
+	var agents []*robotsGroup
+	inRules := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agent := productToken(val)
+			group, ok := file.groups[agent]
+			if !ok {
+				group = &robotsGroup{}
+				file.groups[agent] = group
+			}
+			agents = append(agents, group)
+		case "allow", "disallow":
+			inRules = true
+			if val == "" {
+				// An empty Disallow allows everything, which is the default
+				continue
+			}
+			for _, group := range agents {
+				group.rules = append(group.rules, robotsRule{pattern: val, allow: key == "allow"})
+			}
+		case "sitemap":
+			// Sitemap lines do not belong to a group
+			if val != "" {
+				file.sitemaps = append(file.sitemaps, val)
+			}
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(val, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			for _, group := range agents {
+				group.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+	return file
+}
+
+// group returns the group of the user agent, or of the "*" user agent if it has none.
+// It returns nil when no group applies, which allows everything.
+func (f *robotsFile) group(userAgent string) *robotsGroup {
+	if group, ok := f.groups[productToken(userAgent)]; ok {
+		return group
+	}
+	return f.groups["*"]
+}
+
+// allowed reports whether the path, including the query, may be requested. The rule with the
+// longest matching pattern applies, Allow wins over Disallow when they are as long.
+func (g *robotsGroup) allowed(path string) bool {
+	if g == nil || path == "/robots.txt" {
+		return true
+	}
+
+	longest := -1
+	allow := true
+	for _, rule := range g.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			longest = n
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// matchRobotsPattern reports whether the path matches the pattern of a rule. The pattern
+// matches the start of the path, "*" matches any sequence of characters and a trailing "$"
+// matches the end of the path.
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// productToken returns the lower-cased product token of a user agent, eg. "webpage-analyzer"
+// for "webpage-analyzer/1.0 (+https://...)"
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(token)
+}
+
+// robotsPath returns the path and the query of the URL matched against the rules
+func robotsPath(target *url.URL) string {
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return path
+}
+
+// robotsHost holds the rules of a host and paces the requests to the host by its Crawl-delay
+type robotsHost struct {
+	// ready is closed once the rules are fetched
+	ready    chan struct{}
+	group    *robotsGroup
+	sitemaps []string
+	expires  time.Time
+
+	mu sync.Mutex
+	// next is the earliest start of the next request to the host
+	next time.Time
+}
+
+// robotsCache holds the rules of the hosts by scheme and host
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{hosts: make(map[string]*robotsHost)}
+}
+
+// defaultRobotsCache is used by the zero value of Utils
+var defaultRobotsCache = newRobotsCache()
+
+// robotsRules returns the robots.txt rules of the host of the URL, fetching them if they are not
+// cached. It returns nil when robots.txt is not followed.
+func (u *Utils) robotsRules(ctx context.Context, target *url.URL) (*robotsHost, error) {
+	if !u.Robots.enabled() {
+		return nil, nil
+	}
+	return u.robotsOf(ctx, target)
+}
+
+// robotsOf returns the robots.txt of the host of the URL, fetching it if it is not cached
+func (u *Utils) robotsOf(ctx context.Context, target *url.URL) (*robotsHost, error) {
+	cache := u.robots
+	if cache == nil {
+		cache = defaultRobotsCache
+	}
+	key := target.Scheme + "://" + strings.ToLower(target.Host)
+
+	cache.mu.Lock()
+	host, ok := cache.hosts[key]
+	if ok && host.fetched() && time.Now().After(host.expires) {
+		ok = false
+	}
+	if !ok {
+		host = &robotsHost{ready: make(chan struct{})}
+		cache.hosts[key] = host
+		// The rules are shared by the requests to the host, so their fetch is not canceled
+		// with the request that started it
+		go u.fetchRobots(context.WithoutCancel(ctx), key, host)
+	}
+	cache.mu.Unlock()
+
+	select {
+	case <-host.ready:
+		return host, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (h *robotsHost) fetched() bool {
+	select {
+	case <-h.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// fetchRobots fetches the robots.txt of the scheme and host into the host rules. A missing
+// robots.txt allows everything and a server error disallows everything. A robots.txt that
+// cannot be requested allows everything, so that the request of the URL reports the failure.
+func (u *Utils) fetchRobots(ctx context.Context, key string, host *robotsHost) {
+	defer close(host.ready)
+	host.expires = time.Now().Add(robotsCacheTTL)
+
+	req, err := u.newRequest(ctx, http.MethodGet, key+"/robots.txt", nil)
+	if err != nil {
+		return
+	}
+	client := u.httpClient(withDefault(u.client.FetchTimeout, DefaultFetchTimeout))
+	var redirects []Redirect
+	client.CheckRedirect = u.followRedirects(&redirects)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		host.expires = time.Now().Add(robotsErrorTTL)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		host.group = disallowAll
+		host.expires = time.Now().Add(robotsErrorTTL)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		file := parseRobots(io.LimitReader(resp.Body, maxRobotsSize))
+		host.group = file.group(req.UserAgent())
+		host.sitemaps = file.sitemaps
+	}
+}
+
+// allowed reports whether the URL may be requested. A nil host allows everything.
+func (h *robotsHost) allowed(target *url.URL) bool {
+	return h == nil || h.group.allowed(robotsPath(target))
+}
+
+// wait waits for the Crawl-delay of the host, capped at maxDelay, since the start of the
+// previous request to the host
+func (h *robotsHost) wait(ctx context.Context, maxDelay time.Duration) error {
+	if h == nil || h.group == nil || h.group.crawlDelay <= 0 {
+		return nil
+	}
+	delay := min(h.group.crawlDelay, maxDelay)
+
+	h.mu.Lock()
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	h.next = start.Add(delay)
+	h.mu.Unlock()
+
+	return sleepContext(ctx, start.Sub(now))
+}
+
+func (u *Utils) maxCrawlDelay() time.Duration {
+	return withDefault(u.Robots.MaxCrawlDelay, DefaultMaxCrawlDelay)
+}
+
+// HasNoindex reports whether the robots directives of a robots meta tag or of an X-Robots-Tag
+// header, eg. "noindex, nofollow", ask not to index the page. The directives prefixed with a
+// user agent, eg. "otherbot: noindex", are ignored unless they name the product token of
+// userAgent, the User-Agent the page was requested with.
+func HasNoindex(directives, userAgent string) bool {
+	for _, directive := range strings.Split(directives, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if agent, rest, ok := strings.Cut(directive, ":"); ok {
+			if productToken(agent) != productToken(userAgent) {
+				continue
+			}
+			directive = strings.TrimSpace(rest)
+		}
+		if directive == "noindex" || directive == "none" {
+			return true
+		}
+	}
+	return false
+}
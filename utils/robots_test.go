@@ -0,0 +1,322 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := `
+# Rules of the example site
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?q=*&page=
+
+User-agent: Webpage-Analyzer
+User-agent: OtherBot
+Disallow: /drafts
+Allow: /drafts/published
+Crawl-delay: 1.5
+
+User-agent: webpage-analyzer/2.0
+Disallow: /archive # merged with the group above
+`
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{name: "No matching rule", userAgent: "SomeBot", path: "/about", expected: true},
+		{name: "Disallowed prefix", userAgent: "SomeBot", path: "/private/data", expected: false},
+		{name: "Longer Allow wins", userAgent: "SomeBot", path: "/private/public/page", expected: true},
+		{name: "Anchored wildcard", userAgent: "SomeBot", path: "/docs/guide.pdf", expected: false},
+		{name: "Anchored wildcard not at the end", userAgent: "SomeBot", path: "/docs/guide.pdf?download=1", expected: true},
+		{name: "Wildcard in the query", userAgent: "SomeBot", path: "/search?q=go&page=2", expected: false},
+		{name: "Wildcard not matching", userAgent: "SomeBot", path: "/search?q=go", expected: true},
+		{name: "robots.txt is always allowed", userAgent: "SomeBot", path: "/robots.txt", expected: true},
+		{name: "Own group replaces the default group", userAgent: DefaultUserAgent, path: "/private/data", expected: true},
+		{name: "Own group", userAgent: DefaultUserAgent, path: "/drafts/new", expected: false},
+		{name: "Own group Allow", userAgent: DefaultUserAgent, path: "/drafts/published/1", expected: true},
+		{name: "Merged group", userAgent: DefaultUserAgent, path: "/archive/2020", expected: false},
+		{name: "Group of several user agents", userAgent: "OtherBot/3.1", path: "/drafts", expected: false},
+	}
+
+	file := parseRobots(strings.NewReader(robots))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if allowed := file.group(test.userAgent).allowed(test.path); allowed != test.expected {
+				t.Errorf("Expected allowed %t for '%s', got %t", test.expected, test.path, allowed)
+			}
+		})
+	}
+
+	if delay := file.group(DefaultUserAgent).crawlDelay; delay != 1500*time.Millisecond {
+		t.Errorf("Expected a crawl delay of 1.5s, got %s", delay)
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "/", path: "/anything", expected: true},
+		{pattern: "/fish", path: "/fish.html", expected: true},
+		{pattern: "/fish", path: "/Fish.html", expected: false},
+		{pattern: "/fish*", path: "/fishheads/yummy.html", expected: true},
+		{pattern: "/*.php", path: "/folder/filename.php?parameters", expected: true},
+		{pattern: "/*.php$", path: "/filename.php", expected: true},
+		{pattern: "/*.php$", path: "/filename.php5", expected: false},
+		{pattern: "/fish*.php", path: "/fishheads/catfish.php?parameters", expected: true},
+		{pattern: "/fish*.php", path: "/Fish.PHP", expected: false},
+		{pattern: "/page$", path: "/page", expected: true},
+		{pattern: "/page$", path: "/page/", expected: false},
+		{pattern: "/a*b*c", path: "/a-c-b", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			if matched := matchRobotsPattern(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Expected match %t, got %t", test.expected, matched)
+			}
+		})
+	}
+}
+
+// robotsServer serves the robots.txt of the given status and body and a page on every other
+// path. It counts the requests of robots.txt and of the pages.
+func robotsServer(t *testing.T, status int, robots string) (server *httptest.Server, robotsRequests, pageRequests *atomic.Int32) {
+	robotsRequests, pageRequests = &atomic.Int32{}, &atomic.Int32{}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.WriteHeader(status)
+			io.WriteString(w, robots)
+			return
+		}
+		pageRequests.Add(1)
+		io.WriteString(w, "<html><title>Page</title></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server, robotsRequests, pageRequests
+}
+
+func TestFetchPageRobots(t *testing.T) {
+	tests := []struct {
+		name               string
+		mode               string
+		status             int
+		robots             string
+		expectedErr        error
+		expectedDisallowed bool
+		expectedRequests   int32
+		expectedRobots     int32
+	}{
+		{
+			name:               "Disallowed URL skipped",
+			mode:               RobotsSkip,
+			status:             http.StatusOK,
+			robots:             "User-agent: *\nDisallow: /private",
+			expectedErr:        ErrDisallowedByRobots,
+			expectedDisallowed: true,
+			expectedRequests:   0,
+			expectedRobots:     1,
+		},
+		{
+			name:               "Disallowed URL skipped by default",
+			status:             http.StatusOK,
+			robots:             "User-agent: *\nDisallow: /private",
+			expectedErr:        ErrDisallowedByRobots,
+			expectedDisallowed: true,
+			expectedRequests:   0,
+			expectedRobots:     1,
+		},
+		{
+			name:               "Disallowed URL reported",
+			mode:               RobotsReport,
+			status:             http.StatusOK,
+			robots:             "User-agent: *\nDisallow: /private",
+			expectedDisallowed: true,
+			expectedRequests:   2,
+			expectedRobots:     1,
+		},
+		{
+			name:             "Other user agent disallowed",
+			mode:             RobotsSkip,
+			status:           http.StatusOK,
+			robots:           "User-agent: OtherBot\nDisallow: /",
+			expectedRequests: 2,
+			expectedRobots:   1,
+		},
+		{
+			name:             "Missing robots.txt allows everything",
+			mode:             RobotsSkip,
+			status:           http.StatusNotFound,
+			expectedRequests: 2,
+			expectedRobots:   1,
+		},
+		{
+			name:               "Server error disallows everything",
+			mode:               RobotsSkip,
+			status:             http.StatusServiceUnavailable,
+			expectedErr:        ErrDisallowedByRobots,
+			expectedDisallowed: true,
+			expectedRequests:   0,
+			expectedRobots:     1,
+		},
+		{
+			name:             "robots.txt not followed",
+			mode:             RobotsOff,
+			status:           http.StatusOK,
+			robots:           "User-agent: *\nDisallow: /",
+			expectedRequests: 2,
+			expectedRobots:   0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, robotsRequests, pageRequests := robotsServer(t, test.status, test.robots)
+			u := &Utils{Robots: RobotsPolicy{Mode: test.mode}, robots: newRobotsCache()}
+
+			// The rules are fetched once and cached for the following requests
+			for i := 0; i < 2; i++ {
+				page, err := u.FetchPage(context.Background(), server.URL+"/private/page")
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", test.expectedErr, err)
+				}
+				if page.RobotsDisallowed != test.expectedDisallowed {
+					t.Errorf("Expected disallowed %t, got %t", test.expectedDisallowed, page.RobotsDisallowed)
+				}
+				if err == nil {
+					page.Body.Close()
+				}
+			}
+
+			if n := pageRequests.Load(); n != test.expectedRequests {
+				t.Errorf("Expected %d page requests, got %d", test.expectedRequests, n)
+			}
+			if n := robotsRequests.Load(); n != test.expectedRobots {
+				t.Errorf("Expected %d robots.txt requests, got %d", test.expectedRobots, n)
+			}
+		})
+	}
+}
+
+func TestCheckLinkRobots(t *testing.T) {
+	tests := []struct {
+		name               string
+		mode               string
+		expectedState      string
+		expectedDisallowed bool
+		expectedRequests   int32
+	}{
+		{
+			name:               "Disallowed link skipped",
+			mode:               RobotsSkip,
+			expectedState:      LinkStateDisallowed,
+			expectedDisallowed: true,
+			expectedRequests:   0,
+		},
+		{
+			name:               "Disallowed link reported",
+			mode:               RobotsReport,
+			expectedState:      LinkStateOK,
+			expectedDisallowed: true,
+			expectedRequests:   1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _, pageRequests := robotsServer(t, http.StatusOK, "User-agent: *\nDisallow: /private")
+			u := &Utils{Robots: RobotsPolicy{Mode: test.mode}, robots: newRobotsCache()}
+
+			res := u.CheckLink(context.Background(), server.URL+"/private/page")
+
+			if res.State != test.expectedState {
+				t.Errorf("Expected state '%s', got '%s'", test.expectedState, res.State)
+			}
+			if !res.Accessible {
+				t.Error("Expected the link to be accessible")
+			}
+			if res.RobotsDisallowed != test.expectedDisallowed {
+				t.Errorf("Expected disallowed %t, got %t", test.expectedDisallowed, res.RobotsDisallowed)
+			}
+			if n := pageRequests.Load(); n != test.expectedRequests {
+				t.Errorf("Expected %d link requests, got %d", test.expectedRequests, n)
+			}
+		})
+	}
+}
+
+func TestRobotsCrawlDelay(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxCrawlDelay time.Duration
+		expectedDelay time.Duration
+	}{
+		{name: "Crawl-delay honoured", expectedDelay: 200 * time.Millisecond},
+		{name: "Crawl-delay capped", maxCrawlDelay: 50 * time.Millisecond, expectedDelay: 50 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _, _ := robotsServer(t, http.StatusOK, "User-agent: *\nCrawl-delay: 0.2")
+			u := &Utils{Robots: RobotsPolicy{Mode: RobotsSkip, MaxCrawlDelay: test.maxCrawlDelay}, robots: newRobotsCache()}
+
+			u.CheckLink(context.Background(), server.URL+"/a")
+			start := time.Now()
+			u.CheckLink(context.Background(), server.URL+"/b")
+			elapsed := time.Since(start)
+
+			if elapsed < test.expectedDelay/2 || elapsed > test.expectedDelay+150*time.Millisecond {
+				t.Errorf("Expected the second request after about %s, got %s", test.expectedDelay, elapsed)
+			}
+		})
+	}
+}
+
+func TestHasNoindex(t *testing.T) {
+	tests := []struct {
+		directives string
+		userAgent  string
+		expected   bool
+	}{
+		{directives: "", expected: false},
+		{directives: "index, follow", expected: false},
+		{directives: "noindex", expected: true},
+		{directives: "NOINDEX, nofollow", expected: true},
+		{directives: "none", expected: true},
+		{directives: "nofollow,noarchive", expected: false},
+		{directives: "otherbot: noindex", expected: false},
+		{directives: "webpage-analyzer: noindex", expected: true},
+		{directives: "nightly-report: noindex", userAgent: "nightly-report/2.0", expected: true},
+		{directives: "webpage-analyzer: noindex", userAgent: "nightly-report/2.0", expected: false},
+		{directives: "unavailable_after: 2030-01-01", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.directives+" "+test.userAgent, func(t *testing.T) {
+			userAgent := test.userAgent
+			if userAgent == "" {
+				userAgent = DefaultUserAgent
+			}
+			if noindex := HasNoindex(test.directives, userAgent); noindex != test.expected {
+				t.Errorf("Expected noindex %t, got %t", test.expected, noindex)
+			}
+		})
+	}
+}
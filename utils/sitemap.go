@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Limits of the sitemaps followed by FetchSitemap
+const (
+	// maxSitemapFiles limits the number of sitemap files fetched, including the index files
+	maxSitemapFiles = 50
+	// maxSitemapURLs limits the number of URLs read from the sitemaps, which is the limit of
+	// a single sitemap file of the protocol
+	maxSitemapURLs = 50000
+	// maxSitemapDepth limits the nesting of the sitemap index files
+	maxSitemapDepth = 3
+)
+
+// gzipMagic starts the gzip compressed sitemaps, eg. sitemap.xml.gz
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Sitemap is the list of URLs of one or more sitemap files
+type Sitemap struct {
+	URLs []SitemapURL `json:"urls"`
+	// Files lists the fetched sitemap files, in the order they were fetched
+	Files []string `json:"files"`
+	// Errors lists the sitemap files listed by an index file that could not be fetched or parsed
+	Errors []SitemapError `json:"errors,omitempty"`
+}
+
+// SitemapURL is a URL listed in a sitemap
+type SitemapURL struct {
+	Loc     string `json:"loc" xml:"loc"`
+	LastMod string `json:"lastmod,omitempty" xml:"lastmod"`
+}
+
+// SitemapError is the error of a sitemap file that could not be fetched or parsed
+type SitemapError struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// sitemapDocument is a <urlset> or a <sitemapindex> sitemap file
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []SitemapURL `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// FetchSitemap fetches the sitemap file and, if it is a sitemap index, the sitemap files it lists.
+// Gzip compressed files are decompressed. The URLs are returned in the order they are listed,
+// without duplicates. The listed files that cannot be fetched are reported in the errors of the
+// sitemap; an error is only returned if the given file cannot be fetched.
+func (u *Utils) FetchSitemap(ctx context.Context, sitemapURL string) (*Sitemap, error) {
+	sitemap := &Sitemap{URLs: []SitemapURL{}, Files: []string{}}
+	seen := make(map[string]bool)
+	if err := u.fetchSitemapFile(ctx, sitemapURL, 0, sitemap, seen); err != nil {
+		return nil, err
+	}
+	return sitemap, nil
+}
+
+// fetchSitemapFile fetches a sitemap file into the sitemap and follows the files of an index
+func (u *Utils) fetchSitemapFile(ctx context.Context, sitemapURL string, depth int, sitemap *Sitemap, seen map[string]bool) error {
+	doc, err := u.fetchSitemapDocument(ctx, sitemapURL)
+	if err != nil {
+		return err
+	}
+	sitemap.Files = append(sitemap.Files, sitemapURL)
+
+	for _, entry := range doc.URLs {
+		entry.Loc = strings.TrimSpace(entry.Loc)
+		entry.LastMod = strings.TrimSpace(entry.LastMod)
+		if entry.Loc == "" || seen[entry.Loc] || len(sitemap.URLs) >= maxSitemapURLs {
+			continue
+		}
+		seen[entry.Loc] = true
+		sitemap.URLs = append(sitemap.URLs, entry)
+	}
+
+	for _, child := range doc.Sitemaps {
+		loc := strings.TrimSpace(child.Loc)
+		switch {
+		case loc == "":
+			continue
+		case depth+1 >= maxSitemapDepth:
+			sitemap.Errors = append(sitemap.Errors, SitemapError{URL: loc, Error: "sitemap index files are nested too deeply"})
+			continue
+		case len(sitemap.Files) >= maxSitemapFiles:
+			sitemap.Errors = append(sitemap.Errors, SitemapError{URL: loc, Error: fmt.Sprintf("more than %d sitemap files", maxSitemapFiles)})
+			continue
+		}
+
+		if err := u.fetchSitemapFile(ctx, loc, depth+1, sitemap, seen); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			sitemap.Errors = append(sitemap.Errors, SitemapError{URL: loc, Error: err.Error()})
+		}
+	}
+	return nil
+}
+
+// fetchSitemapDocument fetches and parses a sitemap file
+func (u *Utils) fetchSitemapDocument(ctx context.Context, sitemapURL string) (*sitemapDocument, error) {
+	target, err := url.ParseRequestURI(sitemapURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedScheme, target.Scheme)
+	}
+
+	robots, err := u.robotsRules(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if !robots.allowed(target) && u.Robots.skip() {
+		return nil, fmt.Errorf("%w: %s", ErrDisallowedByRobots, sitemapURL)
+	}
+	if err := robots.wait(ctx, u.maxCrawlDelay()); err != nil {
+		return nil, err
+	}
+
+	req, err := u.newRequest(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	client := u.httpClient(withDefault(u.client.FetchTimeout, DefaultFetchTimeout))
+	var redirects []Redirect
+	client.CheckRedirect = u.followRedirects(&redirects)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, requestError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	// The transport decodes a gzip Content-Encoding, a .gz file is decompressed here
+	maxSize := withDefault(u.client.MaxBodySize, DefaultMaxBodySize)
+	var body io.Reader = bufio.NewReader(resp.Body)
+	if magic, _ := body.(*bufio.Reader).Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrReadBody, err)
+		}
+		body = gz
+	}
+
+	limited := &io.LimitedReader{R: body, N: maxSize + 1}
+	var doc sitemapDocument
+	if err := xml.NewDecoder(limited).Decode(&doc); err != nil {
+		if limited.N <= 0 {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, maxSize)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSitemap, err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("%w: unexpected root element <%s>", ErrInvalidSitemap, doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// DiscoverSitemaps returns the sitemaps listed in the robots.txt of the host of the site URL,
+// or the sitemap.xml at the root of the host if robots.txt lists none
+func (u *Utils) DiscoverSitemaps(ctx context.Context, siteURL string) ([]string, error) {
+	target, err := url.ParseRequestURI(siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+	if target.Scheme == "" || target.Host == "" {
+		return nil, ErrMissingSchemeOrHost
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedScheme, target.Scheme)
+	}
+
+	robots, err := u.robotsOf(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(robots.sitemaps) > 0 {
+		return robots.sitemaps, nil
+	}
+	return []string{target.Scheme + "://" + target.Host + "/sitemap.xml"}, nil
+}
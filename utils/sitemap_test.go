@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sitemapServer serves the given files by path, the other paths are not found
+func sitemapServer(t *testing.T, files map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, file)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func gzipped(s string) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(s))
+	gz.Close()
+	return buf.String()
+}
+
+func TestFetchSitemap(t *testing.T) {
+	var server *httptest.Server
+	// The files refer to the server with %[1]s
+	files := map[string]string{
+		"/sitemap.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>%[1]s/</loc><lastmod>2024-01-02</lastmod></url>
+	<url><loc> %[1]s/about </loc></url>
+	<url><loc>%[1]s/</loc></url>
+</urlset>`,
+		"/index.xml": `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>%[1]s/sitemap.xml</loc></sitemap>
+	<sitemap><loc>%[1]s/posts.xml.gz</loc></sitemap>
+	<sitemap><loc>%[1]s/missing.xml</loc></sitemap>
+</sitemapindex>`,
+		"/posts.xml.gz": `<urlset><url><loc>%[1]s/posts/1</loc></url><url><loc>%[1]s/about</loc></url></urlset>`,
+		"/page.html":    `<html><body>Not a sitemap</body></html>`,
+	}
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		file = fmt.Sprintf(file, server.URL)
+		if strings.HasSuffix(r.URL.Path, ".gz") {
+			file = gzipped(file)
+		}
+		io.WriteString(w, file)
+	})
+	server = httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedURLs   []string
+		expectedFiles  []string
+		expectedErrors []string
+		expectedErr    error
+	}{
+		{
+			name:          "URL set",
+			path:          "/sitemap.xml",
+			expectedURLs:  []string{"/", "/about"},
+			expectedFiles: []string{"/sitemap.xml"},
+		},
+		{
+			name:           "Sitemap index with a gzipped sitemap",
+			path:           "/index.xml",
+			expectedURLs:   []string{"/", "/about", "/posts/1"},
+			expectedFiles:  []string{"/index.xml", "/sitemap.xml", "/posts.xml.gz"},
+			expectedErrors: []string{"/missing.xml"},
+		},
+		{
+			name:        "Not found",
+			path:        "/missing.xml",
+			expectedErr: &StatusError{StatusCode: http.StatusNotFound},
+		},
+		{
+			name:        "Not a sitemap",
+			path:        "/page.html",
+			expectedErr: ErrInvalidSitemap,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u := &Utils{}
+			sitemap, err := u.FetchSitemap(context.Background(), server.URL+test.path)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var urls, files, errs []string
+			for _, entry := range sitemap.URLs {
+				urls = append(urls, strings.TrimPrefix(entry.Loc, server.URL))
+			}
+			for _, file := range sitemap.Files {
+				files = append(files, strings.TrimPrefix(file, server.URL))
+			}
+			for _, e := range sitemap.Errors {
+				errs = append(errs, strings.TrimPrefix(e.URL, server.URL))
+			}
+			if !reflect.DeepEqual(urls, test.expectedURLs) {
+				t.Errorf("Expected URLs %v, got %v", test.expectedURLs, urls)
+			}
+			if !reflect.DeepEqual(files, test.expectedFiles) {
+				t.Errorf("Expected files %v, got %v", test.expectedFiles, files)
+			}
+			if !reflect.DeepEqual(errs, test.expectedErrors) {
+				t.Errorf("Expected errors for %v, got %v", test.expectedErrors, sitemap.Errors)
+			}
+			if sitemap.URLs[0].LastMod != "2024-01-02" {
+				t.Errorf("Expected the lastmod of the first URL, got '%s'", sitemap.URLs[0].LastMod)
+			}
+		})
+	}
+}
+
+func TestDiscoverSitemaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		robots   map[string]string
+		expected []string
+	}{
+		{
+			name:     "Listed in robots.txt",
+			robots:   map[string]string{"/robots.txt": "User-agent: *\nDisallow: /private\n\nSitemap: https://cdn.example.com/sitemap.xml\nsitemap: https://example.com/news.xml\n"},
+			expected: []string{"https://cdn.example.com/sitemap.xml", "https://example.com/news.xml"},
+		},
+		{
+			name:     "Default sitemap",
+			robots:   map[string]string{"/robots.txt": "User-agent: *\nDisallow:\n"},
+			expected: []string{"/sitemap.xml"},
+		},
+		{
+			name:     "Missing robots.txt",
+			expected: []string{"/sitemap.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := sitemapServer(t, test.robots)
+			u := &Utils{robots: newRobotsCache()}
+
+			sitemaps, err := u.DiscoverSitemaps(context.Background(), server.URL+"/blog/post")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for i := range sitemaps {
+				sitemaps[i] = strings.TrimPrefix(sitemaps[i], server.URL)
+			}
+			if !reflect.DeepEqual(sitemaps, test.expected) {
+				t.Errorf("Expected sitemaps %v, got %v", test.expected, sitemaps)
+			}
+		})
+	}
+}
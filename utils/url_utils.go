@@ -1,7 +1,7 @@
 package utils
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -11,34 +11,156 @@ import (
 	"time"
 )
 
+// Page is a fetched page
+type Page struct {
+	// URL is the final URL of the page, after the redirects
+	URL string
+	// Body streams the decoded body of the page transcoded to UTF-8. It fails with
+	// ErrBodyTooLarge once more than the maximum body size is read and must be closed by the caller.
+	Body io.ReadCloser
+	// Charset is the character encoding detected from the Content-Type header and the start of the body
+	Charset Charset
+	// Redirects is the redirect chain from the requested URL to the final URL
+	Redirects []Redirect
+	// TransferredBytes and DecodedBytes count the bytes of the body read so far, before and
+	// after the content decoding
+	TransferredBytes int64
+	DecodedBytes     int64
+	// RobotsDisallowed reports that the robots.txt of the host disallows the URL
+	RobotsDisallowed bool
+	// StatusCode is the status code of the final response, zero if no response was received
+	StatusCode int
+	// Noindex reports that the X-Robots-Tag header of the response asks not to index the page
+	Noindex bool
+	// UserAgent is the User-Agent the page was requested with
+	UserAgent string
+}
+
 // FetchURL fetches the specified URL and return the HTML content as a string
 func (u *Utils) FetchURL(rawURL string) (string, error) {
-	parsedURL, err := url.ParseRequestURI(rawURL)
+	return u.FetchURLContext(context.Background(), rawURL)
+}
+
+// FetchURLContext fetches the specified URL and return the HTML content as a string.
+// The request is aborted when the context is canceled.
+func (u *Utils) FetchURLContext(ctx context.Context, rawURL string) (string, error) {
+	page, err := u.FetchPage(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer page.Body.Close()
+
+	body, err := io.ReadAll(page.Body)
 	if err != nil {
 		return "", err
 	}
+	return string(body), nil
+}
+
+// FetchPage fetches the specified URL following its redirects, and returns the page with its
+// final URL, the redirect chain and the body to stream. The request is aborted when the context
+// is canceled. Failures until the response headers are received are retried according to the
+// retry policy, the failures while reading the body are not. The robots.txt of the host is
+// followed according to the robots policy.
+// On error the returned page holds the redirects followed by the last attempt, if any.
+func (u *Utils) FetchPage(ctx context.Context, rawURL string) (*Page, error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
 
 	if parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return "", errors.New("invalid URL: missing scheme or host")
+		return nil, ErrMissingSchemeOrHost
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedScheme, parsedURL.Scheme)
+	}
+
+	robots, err := u.robotsRules(ctx, parsedURL)
+	if err != nil {
+		return nil, err
+	}
+	disallowed := !robots.allowed(parsedURL)
+	if disallowed && u.Robots.skip() {
+		return &Page{URL: parsedURL.String(), RobotsDisallowed: true}, fmt.Errorf("%w: %s", ErrDisallowedByRobots, parsedURL)
+	}
+
+	page := &Page{}
+	for attempt := 1; ; attempt++ {
+		if err := robots.wait(ctx, u.maxCrawlDelay()); err != nil {
+			return page, err
+		}
+		statusCode, retryAfter, err := u.fetchOnce(ctx, parsedURL.String(), page)
+		page.RobotsDisallowed = disallowed
+
+		delay, retry := u.Retry.nextDelay(attempt, statusCode, retryAfter, err, u.maxRetryAfter())
+		if retry {
+			// A canceled backoff fails the fetch with the error of the context
+			if err := sleepContext(ctx, delay); err != nil {
+				return page, err
+			}
+			continue
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return page, ctx.Err()
+			}
+			return page, err
+		}
+		if statusCode != http.StatusOK {
+			return page, &StatusError{StatusCode: statusCode}
+		}
+		return page, nil
+	}
+}
+
+// fetchOnce makes a single attempt to fetch the URL into the page, and returns the status code
+// and the Retry-After delay of the response. The body of a 200 response is left open in the page.
+func (u *Utils) fetchOnce(ctx context.Context, rawURL string, page *Page) (int, time.Duration, error) {
+	*page = Page{URL: rawURL}
+
+	req, err := u.newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", ErrInvalidURL, err)
 	}
+	// The body is decompressed by pageBody rather than the transport, to count the transferred bytes
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	client := u.httpClient(withDefault(u.client.FetchTimeout, DefaultFetchTimeout))
+	client.CheckRedirect = u.followRedirects(&page.Redirects)
 
-	resp, err := http.Get(parsedURL.String())
+	resp, err := client.Do(req)
 	if err != nil {
-		return "", errors.New("unable to fetch the URL")
+		return 0, 0, requestError(err)
 	}
-	defer resp.Body.Close()
 
+	page.URL = resp.Request.URL.String()
+	page.StatusCode = resp.StatusCode
+	page.UserAgent = resp.Request.UserAgent()
+	page.Noindex = HasNoindex(strings.Join(resp.Header.Values("X-Robots-Tag"), ","), page.UserAgent)
 	if resp.StatusCode != http.StatusOK {
-		errMsg := fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
-		return "", errors.New(errMsg)
+		resp.Body.Close()
+		return resp.StatusCode, retryAfterOf(resp), nil
+	}
+
+	maxSize := withDefault(u.client.MaxBodySize, DefaultMaxBodySize)
+	if resp.ContentLength > maxSize && resp.Header.Get("Content-Encoding") == "" {
+		resp.Body.Close()
+		return resp.StatusCode, 0, fmt.Errorf("%w: %d bytes", ErrBodyTooLarge, resp.ContentLength)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := newPageBody(resp, page, maxSize)
 	if err != nil {
-		return "", errors.New("error reading the response body")
+		resp.Body.Close()
+		return resp.StatusCode, 0, err
 	}
+	page.Body, page.Charset = decodeBody(body, resp.Header.Get("Content-Type"))
 
-	return string(body), nil
+	return resp.StatusCode, -1, nil
 }
 
 // IsInternalLink checks whether the given targetURL is internal to the baseURL
@@ -64,19 +186,34 @@ func (u *Utils) IsInternalLink(baseURL string, targetURL string) bool {
 	return hasSameHost
 }
 
-// IsLinkAccessible checks whether the link is accessible
-// Assumption: If the http.Head request timeouts in 5 seconds then the url is inaccessible
-func (u *Utils) IsLinkAccessible(link string) bool {
-	client := http.Client{
-		Timeout: 5 * time.Second,
+// ResolveURL resolves the href of a link against the given base URL and removes the
+// fragment, so that the result can be requested and compared with other links
+func (u *Utils) ResolveURL(baseURL string, href string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
 	}
 
-	res, err := client.Head(link)
+	ref, err := url.Parse(strings.TrimSpace(href))
 	if err != nil {
-		log.Println(err)
-		return false
+		return "", err
 	}
-	defer res.Body.Close()
 
-	return res.StatusCode < 400
+	resolved := base.ResolveReference(ref)
+	resolved.Fragment = ""
+	resolved.RawFragment = ""
+
+	return resolved.String(), nil
+}
+
+// IsLinkAccessible checks whether the link is accessible. A link whose check exceeds the
+// LinkCheckTimeout of the client is inaccessible.
+func (u *Utils) IsLinkAccessible(link string) bool {
+	return u.IsLinkAccessibleContext(context.Background(), link)
+}
+
+// IsLinkAccessibleContext checks whether the link is accessible. The check is aborted
+// when the context is canceled, in which case the link is reported as inaccessible.
+func (u *Utils) IsLinkAccessibleContext(ctx context.Context, link string) bool {
+	return u.CheckLink(ctx, link).Accessible
 }
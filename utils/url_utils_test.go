@@ -1,12 +1,15 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestFetchURL(t *testing.T) {
@@ -30,14 +33,14 @@ func TestFetchURL(t *testing.T) {
 			statusCode:    http.StatusNotFound,
 			responseBody:  "",
 			expectedBody:  "",
-			expectedError: fmt.Errorf("unexpected status code: %d", http.StatusNotFound),
+			expectedError: &StatusError{StatusCode: http.StatusNotFound},
 		},
 		{
 			name:          "Network error",
 			statusCode:    0,
 			responseBody:  "",
 			expectedBody:  "",
-			expectedError: errors.New("unable to fetch the URL"),
+			expectedError: ErrDNS,
 		},
 		{
 			name:          "Read response body error",
@@ -56,7 +59,7 @@ func TestFetchURL(t *testing.T) {
 				if body != test.expectedBody {
 					t.Errorf("expected body %q, got %q", test.expectedBody, body)
 				}
-				if err == nil || err.Error() != test.expectedError.Error() {
+				if !errors.Is(err, test.expectedError) {
 					t.Errorf("expected error %q, got %v", test.expectedError.Error(), err)
 				}
 				return
@@ -76,7 +79,7 @@ func TestFetchURL(t *testing.T) {
 
 			if (err != nil && test.expectedError == nil) || (err == nil && test.expectedError != nil) {
 				t.Errorf("Expected error %v, got %v", test.expectedError, err)
-			} else if err != nil && test.expectedError != nil && err.Error() != test.expectedError.Error() {
+			} else if err != nil && !errors.Is(err, test.expectedError) {
 				t.Errorf("Expected error %q, got %q", test.expectedError.Error(), err.Error())
 			}
 		})
@@ -127,6 +130,60 @@ func TestIsInternalLink(t *testing.T) {
 	}
 }
 
+func TestResolveURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		baseURL       string
+		href          string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "Absolute link",
+			baseURL:  "https://www.google.com/search",
+			href:     "https://www.yahoo.com/",
+			expected: "https://www.yahoo.com/",
+		},
+		{
+			name:     "Relative link",
+			baseURL:  "https://www.google.com/docs/index.html",
+			href:     "about.html",
+			expected: "https://www.google.com/docs/about.html",
+		},
+		{
+			name:     "Root relative link",
+			baseURL:  "https://www.google.com/docs/index.html",
+			href:     "/about",
+			expected: "https://www.google.com/about",
+		},
+		{
+			name:     "Fragment is removed",
+			baseURL:  "https://www.google.com/docs/index.html",
+			href:     " #top ",
+			expected: "https://www.google.com/docs/index.html",
+		},
+		{
+			name:          "Invalid link",
+			baseURL:       "https://www.google.com/",
+			href:          ";;:::12abc",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := utils.ResolveURL(test.baseURL, test.href)
+
+			if test.expectedError != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", test.expectedError, err)
+			}
+			if resolved != test.expected {
+				t.Errorf("Expected '%s', got '%s'", test.expected, resolved)
+			}
+		})
+	}
+}
+
 func TestIsLinkAccessible(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -157,8 +214,12 @@ func TestIsLinkAccessible(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			// Mock server
+			// Mock server. Its robots.txt is missing, so the link is requested.
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					http.NotFound(w, r)
+					return
+				}
 				w.WriteHeader(test.statusCode)
 			}))
 			defer server.Close()
@@ -171,3 +232,298 @@ func TestIsLinkAccessible(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchURLInvalidURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedError error
+	}{
+		{
+			name:          "Malformed URL",
+			url:           "example",
+			expectedError: ErrInvalidURL,
+		},
+		{
+			name:          "Missing host",
+			url:           "http://",
+			expectedError: ErrMissingSchemeOrHost,
+		},
+		{
+			name:          "Unsupported scheme",
+			url:           "ftp://example.com/file.html",
+			expectedError: ErrUnsupportedScheme,
+		},
+		{
+			name:          "Local file",
+			url:           "file://localhost/etc/passwd",
+			expectedError: ErrUnsupportedScheme,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := utils.FetchURL(test.url)
+
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf("Expected error %v, got %v", test.expectedError, err)
+			}
+			if !errors.Is(err, ErrInvalidURL) {
+				t.Errorf("Expected the error to be an %v, got %v", ErrInvalidURL, err)
+			}
+		})
+	}
+}
+
+func TestFetchURLRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "Success!")
+	}))
+	defer server.Close()
+
+	u := &Utils{Retry: RetryPolicy{BaseDelay: time.Millisecond}}
+
+	body, err := u.FetchURL(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body != "Success!" {
+		t.Errorf("Expected body 'Success!', got '%s'", body)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestRetryBackoffCanceled(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		fetch func(ctx context.Context, u *Utils) error
+	}{
+		{
+			name: "Page fetch",
+			fetch: func(ctx context.Context, u *Utils) error {
+				_, err := u.FetchURLContext(ctx, server.URL)
+				return err
+			},
+		},
+		{
+			name: "Link check",
+			fetch: func(ctx context.Context, u *Utils) error {
+				check := u.CheckLink(ctx, server.URL)
+				if check.ErrorClass != ErrorClassTimeout {
+					return fmt.Errorf("error class %q", check.ErrorClass)
+				}
+				return context.DeadlineExceeded
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts.Store(0)
+			u := &Utils{Retry: RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}}
+
+			// The context expires during the backoff after the first attempt
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := test.fetch(ctx, u)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected error %v, got %v", context.DeadlineExceeded, err)
+			}
+			if attempts.Load() != 1 {
+				t.Errorf("Expected 1 attempt, got %d", attempts.Load())
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected the canceled backoff to return promptly, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestFetchURLContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	body, err := utils.FetchURLContext(ctx, server.URL)
+	if body != "" {
+		t.Errorf("Expected empty body, got '%s'", body)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestIsLinkAccessibleContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if utils.IsLinkAccessibleContext(ctx, server.URL) {
+		t.Error("Expected the canceled check to report the link as inaccessible")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the canceled check to return promptly, took %s", elapsed)
+	}
+}
+
+func TestFetchPageRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Landed!")
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/back", http.StatusFound)
+	})
+	mux.HandleFunc("/back", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		path              string
+		maxRedirects      int
+		expectedURL       string
+		expectedRedirects []Redirect
+		expectedErr       error
+	}{
+		{
+			name:        "No redirect",
+			path:        "/final",
+			expectedURL: server.URL + "/final",
+		},
+		{
+			name:        "Redirect chain",
+			path:        "/start",
+			expectedURL: server.URL + "/final",
+			expectedRedirects: []Redirect{
+				{URL: server.URL + "/start", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/middle"},
+				{URL: server.URL + "/middle", StatusCode: http.StatusFound, Location: server.URL + "/final"},
+			},
+		},
+		{
+			name:         "Too many redirects",
+			path:         "/start",
+			maxRedirects: 1,
+			expectedRedirects: []Redirect{
+				{URL: server.URL + "/start", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/middle"},
+				{URL: server.URL + "/middle", StatusCode: http.StatusFound, Location: server.URL + "/final"},
+			},
+			expectedErr: ErrTooManyRedirects,
+		},
+		{
+			name: "Redirect loop",
+			path: "/loop",
+			expectedRedirects: []Redirect{
+				{URL: server.URL + "/loop", StatusCode: http.StatusFound, Location: server.URL + "/back"},
+				{URL: server.URL + "/back", StatusCode: http.StatusFound, Location: server.URL + "/loop"},
+			},
+			expectedErr: ErrRedirectLoop,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := New(ClientConfig{MaxRedirects: test.maxRedirects, AllowHosts: loopback})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			page, err := u.FetchPage(context.Background(), server.URL+test.path)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected error '%v', got '%v'", test.expectedErr, err)
+			}
+			if test.expectedErr == nil && page.URL != test.expectedURL {
+				t.Errorf("Expected final URL '%s', got '%s'", test.expectedURL, page.URL)
+			}
+			if len(page.Redirects) != len(test.expectedRedirects) {
+				t.Fatalf("Expected redirects %v, got %v", test.expectedRedirects, page.Redirects)
+			}
+			for i, redirect := range page.Redirects {
+				if redirect != test.expectedRedirects[i] {
+					t.Errorf("Expected redirect %v, got %v", test.expectedRedirects[i], redirect)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchPageNoindex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Robots-Tag", "nightly-report: noindex")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		userAgent string
+		expected  bool
+	}{
+		{name: "Default user agent", expected: false},
+		{name: "Configured user agent", userAgent: "nightly-report/2.0", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := New(ClientConfig{UserAgent: test.userAgent, AllowHosts: loopback})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			page, err := u.FetchPage(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer page.Body.Close()
+
+			if page.Noindex != test.expected {
+				t.Errorf("Expected noindex %t, got %t", test.expected, page.Noindex)
+			}
+		})
+	}
+}
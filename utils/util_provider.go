@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -14,12 +17,35 @@ type UtilProvider interface {
 	HasLoginForm(n *html.Node) bool
 	ExtractAttribute(n *html.Node, attr string) string
 	IsLinkAccessible(link string) bool
+	IsLinkAccessibleContext(ctx context.Context, link string) bool
+	CheckLink(ctx context.Context, link string) LinkCheck
 	IsInternalLink(baseURL string, targetURL string) bool
+	ResolveURL(baseURL string, href string) (string, error)
 	ExtractHTMLVersion(htmlContent string) string
-	ParseHTML(pageHTML string) (*html.Node, error)
+	ParseHTML(r io.Reader) (*html.Node, error)
 	FetchURL(url string) (string, error)
+	FetchURLContext(ctx context.Context, url string) (string, error)
+	FetchPage(ctx context.Context, url string) (*Page, error)
+	FetchSitemap(ctx context.Context, sitemapURL string) (*Sitemap, error)
+	DiscoverSitemaps(ctx context.Context, siteURL string) ([]string, error)
 }
 
 // Utils provides utility functions for handling common operations
 // like rendering templates, parsing HTML, and URL-related tasks.
-type Utils struct{}
+type Utils struct {
+	// LinkStatusStates maps the status codes of the checked links to the link states.
+	// Nil means DefaultLinkStatusStates. The other 4xx and 5xx status codes denote broken links.
+	LinkStatusStates map[int]string
+	// MaxRetryAfter is the longest Retry-After delay a link check waits for before retrying.
+	// Zero means DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+	// Retry is the retry policy of the page fetch and the link checks
+	Retry RetryPolicy
+	// Robots is how the page fetch and the link checks follow the robots.txt of the hosts
+	Robots RobotsPolicy
+
+	// client, transport and robots are set by New, the zero values use the default settings
+	client    ClientConfig
+	transport http.RoundTripper
+	robots    *robotsCache
+}